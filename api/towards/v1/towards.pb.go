@@ -0,0 +1,1538 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: towards/v1/towards.proto
+
+package towardsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetWeatherRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	City  string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	// DD-MM-YYYY or YYYY-MM-DD; today when empty.
+	Date          string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWeatherRequest) Reset() {
+	*x = GetWeatherRequest{}
+	mi := &file_towards_v1_towards_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWeatherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWeatherRequest) ProtoMessage() {}
+
+func (x *GetWeatherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWeatherRequest.ProtoReflect.Descriptor instead.
+func (*GetWeatherRequest) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetWeatherRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *GetWeatherRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+type Weather struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	City       string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Date       string                 `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Conditions string                 `protobuf:"bytes,3,opt,name=conditions,proto3" json:"conditions,omitempty"`
+	Weather    *WeatherMetrics        `protobuf:"bytes,4,opt,name=weather,proto3" json:"weather,omitempty"`
+	Thermal    *ThermalMetrics        `protobuf:"bytes,5,opt,name=thermal,proto3" json:"thermal,omitempty"`
+	Comfort    *ComfortMetrics        `protobuf:"bytes,6,opt,name=comfort,proto3" json:"comfort,omitempty"`
+	Hours      []*Hour                `protobuf:"bytes,7,rep,name=hours,proto3" json:"hours,omitempty"`
+	// Enrichments are unset when the upstream had no data or failed.
+	Country     *CountryMetrics    `protobuf:"bytes,8,opt,name=country,proto3" json:"country,omitempty"`
+	CityStats   *CityMetrics       `protobuf:"bytes,9,opt,name=city_stats,json=cityStats,proto3" json:"city_stats,omitempty"`
+	Earthquakes *EarthquakeMetrics `protobuf:"bytes,10,opt,name=earthquakes,proto3" json:"earthquakes,omitempty"`
+	Alerts      []*Alert           `protobuf:"bytes,11,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	// Unset for past days or when AI is not configured.
+	Forecast      *Forecast `protobuf:"bytes,12,opt,name=forecast,proto3" json:"forecast,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Weather) Reset() {
+	*x = Weather{}
+	mi := &file_towards_v1_towards_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Weather) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Weather) ProtoMessage() {}
+
+func (x *Weather) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Weather.ProtoReflect.Descriptor instead.
+func (*Weather) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Weather) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Weather) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Weather) GetConditions() string {
+	if x != nil {
+		return x.Conditions
+	}
+	return ""
+}
+
+func (x *Weather) GetWeather() *WeatherMetrics {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+func (x *Weather) GetThermal() *ThermalMetrics {
+	if x != nil {
+		return x.Thermal
+	}
+	return nil
+}
+
+func (x *Weather) GetComfort() *ComfortMetrics {
+	if x != nil {
+		return x.Comfort
+	}
+	return nil
+}
+
+func (x *Weather) GetHours() []*Hour {
+	if x != nil {
+		return x.Hours
+	}
+	return nil
+}
+
+func (x *Weather) GetCountry() *CountryMetrics {
+	if x != nil {
+		return x.Country
+	}
+	return nil
+}
+
+func (x *Weather) GetCityStats() *CityMetrics {
+	if x != nil {
+		return x.CityStats
+	}
+	return nil
+}
+
+func (x *Weather) GetEarthquakes() *EarthquakeMetrics {
+	if x != nil {
+		return x.Earthquakes
+	}
+	return nil
+}
+
+func (x *Weather) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+func (x *Weather) GetForecast() *Forecast {
+	if x != nil {
+		return x.Forecast
+	}
+	return nil
+}
+
+type WeatherMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Temperature   float64                `protobuf:"fixed64,1,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	TempMin       float64                `protobuf:"fixed64,2,opt,name=temp_min,json=tempMin,proto3" json:"temp_min,omitempty"`
+	TempMax       float64                `protobuf:"fixed64,3,opt,name=temp_max,json=tempMax,proto3" json:"temp_max,omitempty"`
+	Humidity      float64                `protobuf:"fixed64,4,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	WindSpeed     float64                `protobuf:"fixed64,5,opt,name=wind_speed,json=windSpeed,proto3" json:"wind_speed,omitempty"`
+	Pressure      float64                `protobuf:"fixed64,6,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherMetrics) Reset() {
+	*x = WeatherMetrics{}
+	mi := &file_towards_v1_towards_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherMetrics) ProtoMessage() {}
+
+func (x *WeatherMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherMetrics.ProtoReflect.Descriptor instead.
+func (*WeatherMetrics) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WeatherMetrics) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *WeatherMetrics) GetTempMin() float64 {
+	if x != nil {
+		return x.TempMin
+	}
+	return 0
+}
+
+func (x *WeatherMetrics) GetTempMax() float64 {
+	if x != nil {
+		return x.TempMax
+	}
+	return 0
+}
+
+func (x *WeatherMetrics) GetHumidity() float64 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *WeatherMetrics) GetWindSpeed() float64 {
+	if x != nil {
+		return x.WindSpeed
+	}
+	return 0
+}
+
+func (x *WeatherMetrics) GetPressure() float64 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+type ThermalMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FeelsLike     float64                `protobuf:"fixed64,1,opt,name=feels_like,json=feelsLike,proto3" json:"feels_like,omitempty"`
+	HeatIndex     float64                `protobuf:"fixed64,2,opt,name=heat_index,json=heatIndex,proto3" json:"heat_index,omitempty"`
+	WindChill     float64                `protobuf:"fixed64,3,opt,name=wind_chill,json=windChill,proto3" json:"wind_chill,omitempty"`
+	Humidex       float64                `protobuf:"fixed64,4,opt,name=humidex,proto3" json:"humidex,omitempty"`
+	Utci          float64                `protobuf:"fixed64,5,opt,name=utci,proto3" json:"utci,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThermalMetrics) Reset() {
+	*x = ThermalMetrics{}
+	mi := &file_towards_v1_towards_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThermalMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThermalMetrics) ProtoMessage() {}
+
+func (x *ThermalMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThermalMetrics.ProtoReflect.Descriptor instead.
+func (*ThermalMetrics) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ThermalMetrics) GetFeelsLike() float64 {
+	if x != nil {
+		return x.FeelsLike
+	}
+	return 0
+}
+
+func (x *ThermalMetrics) GetHeatIndex() float64 {
+	if x != nil {
+		return x.HeatIndex
+	}
+	return 0
+}
+
+func (x *ThermalMetrics) GetWindChill() float64 {
+	if x != nil {
+		return x.WindChill
+	}
+	return 0
+}
+
+func (x *ThermalMetrics) GetHumidex() float64 {
+	if x != nil {
+		return x.Humidex
+	}
+	return 0
+}
+
+func (x *ThermalMetrics) GetUtci() float64 {
+	if x != nil {
+		return x.Utci
+	}
+	return 0
+}
+
+type ComfortMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         float64                `protobuf:"fixed64,1,opt,name=index,proto3" json:"index,omitempty"`
+	AirPurity     int32                  `protobuf:"varint,2,opt,name=air_purity,json=airPurity,proto3" json:"air_purity,omitempty"`
+	RoadTraffic   int32                  `protobuf:"varint,3,opt,name=road_traffic,json=roadTraffic,proto3" json:"road_traffic,omitempty"`
+	CrimeRisks    int32                  `protobuf:"varint,4,opt,name=crime_risks,json=crimeRisks,proto3" json:"crime_risks,omitempty"`
+	BestHours     []string               `protobuf:"bytes,5,rep,name=best_hours,json=bestHours,proto3" json:"best_hours,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComfortMetrics) Reset() {
+	*x = ComfortMetrics{}
+	mi := &file_towards_v1_towards_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComfortMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComfortMetrics) ProtoMessage() {}
+
+func (x *ComfortMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComfortMetrics.ProtoReflect.Descriptor instead.
+func (*ComfortMetrics) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ComfortMetrics) GetIndex() float64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ComfortMetrics) GetAirPurity() int32 {
+	if x != nil {
+		return x.AirPurity
+	}
+	return 0
+}
+
+func (x *ComfortMetrics) GetRoadTraffic() int32 {
+	if x != nil {
+		return x.RoadTraffic
+	}
+	return 0
+}
+
+func (x *ComfortMetrics) GetCrimeRisks() int32 {
+	if x != nil {
+		return x.CrimeRisks
+	}
+	return 0
+}
+
+func (x *ComfortMetrics) GetBestHours() []string {
+	if x != nil {
+		return x.BestHours
+	}
+	return nil
+}
+
+type Hour struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          string                 `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Temperature   float64                `protobuf:"fixed64,2,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	FeelsLike     float64                `protobuf:"fixed64,3,opt,name=feels_like,json=feelsLike,proto3" json:"feels_like,omitempty"`
+	Humidity      float64                `protobuf:"fixed64,4,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	PrecipProb    float64                `protobuf:"fixed64,5,opt,name=precip_prob,json=precipProb,proto3" json:"precip_prob,omitempty"`
+	WindSpeed     float64                `protobuf:"fixed64,6,opt,name=wind_speed,json=windSpeed,proto3" json:"wind_speed,omitempty"`
+	UvIndex       float64                `protobuf:"fixed64,7,opt,name=uv_index,json=uvIndex,proto3" json:"uv_index,omitempty"`
+	Conditions    string                 `protobuf:"bytes,8,opt,name=conditions,proto3" json:"conditions,omitempty"`
+	ComfortScore  float64                `protobuf:"fixed64,9,opt,name=comfort_score,json=comfortScore,proto3" json:"comfort_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hour) Reset() {
+	*x = Hour{}
+	mi := &file_towards_v1_towards_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hour) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hour) ProtoMessage() {}
+
+func (x *Hour) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hour.ProtoReflect.Descriptor instead.
+func (*Hour) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Hour) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *Hour) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Hour) GetFeelsLike() float64 {
+	if x != nil {
+		return x.FeelsLike
+	}
+	return 0
+}
+
+func (x *Hour) GetHumidity() float64 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *Hour) GetPrecipProb() float64 {
+	if x != nil {
+		return x.PrecipProb
+	}
+	return 0
+}
+
+func (x *Hour) GetWindSpeed() float64 {
+	if x != nil {
+		return x.WindSpeed
+	}
+	return 0
+}
+
+func (x *Hour) GetUvIndex() float64 {
+	if x != nil {
+		return x.UvIndex
+	}
+	return 0
+}
+
+func (x *Hour) GetConditions() string {
+	if x != nil {
+		return x.Conditions
+	}
+	return ""
+}
+
+func (x *Hour) GetComfortScore() float64 {
+	if x != nil {
+		return x.ComfortScore
+	}
+	return 0
+}
+
+type CountryMetrics struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GdpUsd            float64                `protobuf:"fixed64,1,opt,name=gdp_usd,json=gdpUsd,proto3" json:"gdp_usd,omitempty"`
+	Population        int64                  `protobuf:"varint,2,opt,name=population,proto3" json:"population,omitempty"`
+	PopulationDensity float64                `protobuf:"fixed64,3,opt,name=population_density,json=populationDensity,proto3" json:"population_density,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CountryMetrics) Reset() {
+	*x = CountryMetrics{}
+	mi := &file_towards_v1_towards_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountryMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountryMetrics) ProtoMessage() {}
+
+func (x *CountryMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountryMetrics.ProtoReflect.Descriptor instead.
+func (*CountryMetrics) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CountryMetrics) GetGdpUsd() float64 {
+	if x != nil {
+		return x.GdpUsd
+	}
+	return 0
+}
+
+func (x *CountryMetrics) GetPopulation() int64 {
+	if x != nil {
+		return x.Population
+	}
+	return 0
+}
+
+func (x *CountryMetrics) GetPopulationDensity() float64 {
+	if x != nil {
+		return x.PopulationDensity
+	}
+	return 0
+}
+
+type CityMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Population    int64                  `protobuf:"varint,1,opt,name=population,proto3" json:"population,omitempty"`
+	DensityPerKm2 float64                `protobuf:"fixed64,2,opt,name=density_per_km2,json=densityPerKm2,proto3" json:"density_per_km2,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CityMetrics) Reset() {
+	*x = CityMetrics{}
+	mi := &file_towards_v1_towards_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CityMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CityMetrics) ProtoMessage() {}
+
+func (x *CityMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CityMetrics.ProtoReflect.Descriptor instead.
+func (*CityMetrics) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CityMetrics) GetPopulation() int64 {
+	if x != nil {
+		return x.Population
+	}
+	return 0
+}
+
+func (x *CityMetrics) GetDensityPerKm2() float64 {
+	if x != nil {
+		return x.DensityPerKm2
+	}
+	return 0
+}
+
+type EarthquakeMetrics struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Risk           float64                `protobuf:"fixed64,1,opt,name=risk,proto3" json:"risk,omitempty"`
+	Count          int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	MaxMag         float64                `protobuf:"fixed64,3,opt,name=max_mag,json=maxMag,proto3" json:"max_mag,omitempty"`
+	MaxMagLastWeek float64                `protobuf:"fixed64,4,opt,name=max_mag_last_week,json=maxMagLastWeek,proto3" json:"max_mag_last_week,omitempty"`
+	Recent         []*Earthquake          `protobuf:"bytes,5,rep,name=recent,proto3" json:"recent,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EarthquakeMetrics) Reset() {
+	*x = EarthquakeMetrics{}
+	mi := &file_towards_v1_towards_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EarthquakeMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EarthquakeMetrics) ProtoMessage() {}
+
+func (x *EarthquakeMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EarthquakeMetrics.ProtoReflect.Descriptor instead.
+func (*EarthquakeMetrics) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *EarthquakeMetrics) GetRisk() float64 {
+	if x != nil {
+		return x.Risk
+	}
+	return 0
+}
+
+func (x *EarthquakeMetrics) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *EarthquakeMetrics) GetMaxMag() float64 {
+	if x != nil {
+		return x.MaxMag
+	}
+	return 0
+}
+
+func (x *EarthquakeMetrics) GetMaxMagLastWeek() float64 {
+	if x != nil {
+		return x.MaxMagLastWeek
+	}
+	return 0
+}
+
+func (x *EarthquakeMetrics) GetRecent() []*Earthquake {
+	if x != nil {
+		return x.Recent
+	}
+	return nil
+}
+
+type Earthquake struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Magnitude     float64                `protobuf:"fixed64,2,opt,name=magnitude,proto3" json:"magnitude,omitempty"`
+	Place         string                 `protobuf:"bytes,3,opt,name=place,proto3" json:"place,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Earthquake) Reset() {
+	*x = Earthquake{}
+	mi := &file_towards_v1_towards_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Earthquake) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Earthquake) ProtoMessage() {}
+
+func (x *Earthquake) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Earthquake.ProtoReflect.Descriptor instead.
+func (*Earthquake) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Earthquake) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Earthquake) GetMagnitude() float64 {
+	if x != nil {
+		return x.Magnitude
+	}
+	return 0
+}
+
+func (x *Earthquake) GetPlace() string {
+	if x != nil {
+		return x.Place
+	}
+	return ""
+}
+
+type Alert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Guidance      string                 `protobuf:"bytes,4,opt,name=guidance,proto3" json:"guidance,omitempty"`
+	Metric        string                 `protobuf:"bytes,5,opt,name=metric,proto3" json:"metric,omitempty"`
+	Value         float64                `protobuf:"fixed64,6,opt,name=value,proto3" json:"value,omitempty"`
+	Threshold     float64                `protobuf:"fixed64,7,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_towards_v1_towards_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Alert) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Alert) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Alert) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Alert) GetGuidance() string {
+	if x != nil {
+		return x.Guidance
+	}
+	return ""
+}
+
+func (x *Alert) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *Alert) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Alert) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+type Forecast struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// REST error code when generation failed, e.g. UPSTREAM_AI_RATE_LIMITED.
+	ErrorCode     string `protobuf:"bytes,2,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Forecast) Reset() {
+	*x = Forecast{}
+	mi := &file_towards_v1_towards_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Forecast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Forecast) ProtoMessage() {}
+
+func (x *Forecast) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Forecast.ProtoReflect.Descriptor instead.
+func (*Forecast) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Forecast) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Forecast) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+type CompareCitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cities        []string               `protobuf:"bytes,1,rep,name=cities,proto3" json:"cities,omitempty"`
+	Date          string                 `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareCitiesRequest) Reset() {
+	*x = CompareCitiesRequest{}
+	mi := &file_towards_v1_towards_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareCitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareCitiesRequest) ProtoMessage() {}
+
+func (x *CompareCitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareCitiesRequest.ProtoReflect.Descriptor instead.
+func (*CompareCitiesRequest) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CompareCitiesRequest) GetCities() []string {
+	if x != nil {
+		return x.Cities
+	}
+	return nil
+}
+
+func (x *CompareCitiesRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+type CompareCitiesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Successful cities by descending comfort index, then failed ones.
+	Results       []*CityResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompareCitiesResponse) Reset() {
+	*x = CompareCitiesResponse{}
+	mi := &file_towards_v1_towards_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompareCitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompareCitiesResponse) ProtoMessage() {}
+
+func (x *CompareCitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompareCitiesResponse.ProtoReflect.Descriptor instead.
+func (*CompareCitiesResponse) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CompareCitiesResponse) GetResults() []*CityResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type CityResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	City    string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Weather *Weather               `protobuf:"bytes,2,opt,name=weather,proto3" json:"weather,omitempty"`
+	// REST error code and detail when the city could not be loaded.
+	ErrorCode     string `protobuf:"bytes,3,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CityResult) Reset() {
+	*x = CityResult{}
+	mi := &file_towards_v1_towards_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CityResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CityResult) ProtoMessage() {}
+
+func (x *CityResult) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CityResult.ProtoReflect.Descriptor instead.
+func (*CityResult) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CityResult) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *CityResult) GetWeather() *Weather {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+func (x *CityResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *CityResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type AskRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Prompt string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	// Replaces the default assistant instruction when set.
+	Instruction   string `protobuf:"bytes,2,opt,name=instruction,proto3" json:"instruction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskRequest) Reset() {
+	*x = AskRequest{}
+	mi := &file_towards_v1_towards_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskRequest) ProtoMessage() {}
+
+func (x *AskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskRequest.ProtoReflect.Descriptor instead.
+func (*AskRequest) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AskRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *AskRequest) GetInstruction() string {
+	if x != nil {
+		return x.Instruction
+	}
+	return ""
+}
+
+type AskChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AskChunk) Reset() {
+	*x = AskChunk{}
+	mi := &file_towards_v1_towards_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AskChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AskChunk) ProtoMessage() {}
+
+func (x *AskChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AskChunk.ProtoReflect.Descriptor instead.
+func (*AskChunk) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AskChunk) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ImproveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	City  string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Date  string                 `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	// Metrics to base the suggestions on, typically a Weather response.
+	Metrics       *structpb.Struct `protobuf:"bytes,3,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImproveRequest) Reset() {
+	*x = ImproveRequest{}
+	mi := &file_towards_v1_towards_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImproveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImproveRequest) ProtoMessage() {}
+
+func (x *ImproveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImproveRequest.ProtoReflect.Descriptor instead.
+func (*ImproveRequest) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ImproveRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *ImproveRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ImproveRequest) GetMetrics() *structpb.Struct {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type ImproveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suggestions   string                 `protobuf:"bytes,1,opt,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImproveResponse) Reset() {
+	*x = ImproveResponse{}
+	mi := &file_towards_v1_towards_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImproveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImproveResponse) ProtoMessage() {}
+
+func (x *ImproveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_towards_v1_towards_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImproveResponse.ProtoReflect.Descriptor instead.
+func (*ImproveResponse) Descriptor() ([]byte, []int) {
+	return file_towards_v1_towards_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ImproveResponse) GetSuggestions() string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return ""
+}
+
+var File_towards_v1_towards_proto protoreflect.FileDescriptor
+
+const file_towards_v1_towards_proto_rawDesc = "" +
+	"\n" +
+	"\x18towards/v1/towards.proto\x12\n" +
+	"towards.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\";\n" +
+	"\x11GetWeatherRequest\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\"\xa7\x04\n" +
+	"\aWeather\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12\x1e\n" +
+	"\n" +
+	"conditions\x18\x03 \x01(\tR\n" +
+	"conditions\x124\n" +
+	"\aweather\x18\x04 \x01(\v2\x1a.towards.v1.WeatherMetricsR\aweather\x124\n" +
+	"\athermal\x18\x05 \x01(\v2\x1a.towards.v1.ThermalMetricsR\athermal\x124\n" +
+	"\acomfort\x18\x06 \x01(\v2\x1a.towards.v1.ComfortMetricsR\acomfort\x12&\n" +
+	"\x05hours\x18\a \x03(\v2\x10.towards.v1.HourR\x05hours\x124\n" +
+	"\acountry\x18\b \x01(\v2\x1a.towards.v1.CountryMetricsR\acountry\x126\n" +
+	"\n" +
+	"city_stats\x18\t \x01(\v2\x17.towards.v1.CityMetricsR\tcityStats\x12?\n" +
+	"\vearthquakes\x18\n" +
+	" \x01(\v2\x1d.towards.v1.EarthquakeMetricsR\vearthquakes\x12)\n" +
+	"\x06alerts\x18\v \x03(\v2\x11.towards.v1.AlertR\x06alerts\x120\n" +
+	"\bforecast\x18\f \x01(\v2\x14.towards.v1.ForecastR\bforecast\"\xbf\x01\n" +
+	"\x0eWeatherMetrics\x12 \n" +
+	"\vtemperature\x18\x01 \x01(\x01R\vtemperature\x12\x19\n" +
+	"\btemp_min\x18\x02 \x01(\x01R\atempMin\x12\x19\n" +
+	"\btemp_max\x18\x03 \x01(\x01R\atempMax\x12\x1a\n" +
+	"\bhumidity\x18\x04 \x01(\x01R\bhumidity\x12\x1d\n" +
+	"\n" +
+	"wind_speed\x18\x05 \x01(\x01R\twindSpeed\x12\x1a\n" +
+	"\bpressure\x18\x06 \x01(\x01R\bpressure\"\x9b\x01\n" +
+	"\x0eThermalMetrics\x12\x1d\n" +
+	"\n" +
+	"feels_like\x18\x01 \x01(\x01R\tfeelsLike\x12\x1d\n" +
+	"\n" +
+	"heat_index\x18\x02 \x01(\x01R\theatIndex\x12\x1d\n" +
+	"\n" +
+	"wind_chill\x18\x03 \x01(\x01R\twindChill\x12\x18\n" +
+	"\ahumidex\x18\x04 \x01(\x01R\ahumidex\x12\x12\n" +
+	"\x04utci\x18\x05 \x01(\x01R\x04utci\"\xa8\x01\n" +
+	"\x0eComfortMetrics\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x01R\x05index\x12\x1d\n" +
+	"\n" +
+	"air_purity\x18\x02 \x01(\x05R\tairPurity\x12!\n" +
+	"\froad_traffic\x18\x03 \x01(\x05R\vroadTraffic\x12\x1f\n" +
+	"\vcrime_risks\x18\x04 \x01(\x05R\n" +
+	"crimeRisks\x12\x1d\n" +
+	"\n" +
+	"best_hours\x18\x05 \x03(\tR\tbestHours\"\x97\x02\n" +
+	"\x04Hour\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\tR\x04time\x12 \n" +
+	"\vtemperature\x18\x02 \x01(\x01R\vtemperature\x12\x1d\n" +
+	"\n" +
+	"feels_like\x18\x03 \x01(\x01R\tfeelsLike\x12\x1a\n" +
+	"\bhumidity\x18\x04 \x01(\x01R\bhumidity\x12\x1f\n" +
+	"\vprecip_prob\x18\x05 \x01(\x01R\n" +
+	"precipProb\x12\x1d\n" +
+	"\n" +
+	"wind_speed\x18\x06 \x01(\x01R\twindSpeed\x12\x19\n" +
+	"\buv_index\x18\a \x01(\x01R\auvIndex\x12\x1e\n" +
+	"\n" +
+	"conditions\x18\b \x01(\tR\n" +
+	"conditions\x12#\n" +
+	"\rcomfort_score\x18\t \x01(\x01R\fcomfortScore\"x\n" +
+	"\x0eCountryMetrics\x12\x17\n" +
+	"\agdp_usd\x18\x01 \x01(\x01R\x06gdpUsd\x12\x1e\n" +
+	"\n" +
+	"population\x18\x02 \x01(\x03R\n" +
+	"population\x12-\n" +
+	"\x12population_density\x18\x03 \x01(\x01R\x11populationDensity\"U\n" +
+	"\vCityMetrics\x12\x1e\n" +
+	"\n" +
+	"population\x18\x01 \x01(\x03R\n" +
+	"population\x12&\n" +
+	"\x0fdensity_per_km2\x18\x02 \x01(\x01R\rdensityPerKm2\"\xb1\x01\n" +
+	"\x11EarthquakeMetrics\x12\x12\n" +
+	"\x04risk\x18\x01 \x01(\x01R\x04risk\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\x17\n" +
+	"\amax_mag\x18\x03 \x01(\x01R\x06maxMag\x12)\n" +
+	"\x11max_mag_last_week\x18\x04 \x01(\x01R\x0emaxMagLastWeek\x12.\n" +
+	"\x06recent\x18\x05 \x03(\v2\x16.towards.v1.EarthquakeR\x06recent\"p\n" +
+	"\n" +
+	"Earthquake\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1c\n" +
+	"\tmagnitude\x18\x02 \x01(\x01R\tmagnitude\x12\x14\n" +
+	"\x05place\x18\x03 \x01(\tR\x05place\"\xb1\x01\n" +
+	"\x05Alert\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1a\n" +
+	"\bguidance\x18\x04 \x01(\tR\bguidance\x12\x16\n" +
+	"\x06metric\x18\x05 \x01(\tR\x06metric\x12\x14\n" +
+	"\x05value\x18\x06 \x01(\x01R\x05value\x12\x1c\n" +
+	"\tthreshold\x18\a \x01(\x01R\tthreshold\"=\n" +
+	"\bForecast\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x02 \x01(\tR\terrorCode\"B\n" +
+	"\x14CompareCitiesRequest\x12\x16\n" +
+	"\x06cities\x18\x01 \x03(\tR\x06cities\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\"I\n" +
+	"\x15CompareCitiesResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.towards.v1.CityResultR\aresults\"\x84\x01\n" +
+	"\n" +
+	"CityResult\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12-\n" +
+	"\aweather\x18\x02 \x01(\v2\x13.towards.v1.WeatherR\aweather\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\tR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"F\n" +
+	"\n" +
+	"AskRequest\x12\x16\n" +
+	"\x06prompt\x18\x01 \x01(\tR\x06prompt\x12 \n" +
+	"\vinstruction\x18\x02 \x01(\tR\vinstruction\"\x1e\n" +
+	"\bAskChunk\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"k\n" +
+	"\x0eImproveRequest\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x121\n" +
+	"\ametrics\x18\x03 \x01(\v2\x17.google.protobuf.StructR\ametrics\"3\n" +
+	"\x0fImproveResponse\x12 \n" +
+	"\vsuggestions\x18\x01 \x01(\tR\vsuggestions2\x9c\x02\n" +
+	"\aTowards\x12@\n" +
+	"\n" +
+	"GetWeather\x12\x1d.towards.v1.GetWeatherRequest\x1a\x13.towards.v1.Weather\x12T\n" +
+	"\rCompareCities\x12 .towards.v1.CompareCitiesRequest\x1a!.towards.v1.CompareCitiesResponse\x125\n" +
+	"\x03Ask\x12\x16.towards.v1.AskRequest\x1a\x14.towards.v1.AskChunk0\x01\x12B\n" +
+	"\aImprove\x12\x1a.towards.v1.ImproveRequest\x1a\x1b.towards.v1.ImproveResponseBEZCgithub.com/publicthrone547/towards_project/api/towards/v1;towardsv1b\x06proto3"
+
+var (
+	file_towards_v1_towards_proto_rawDescOnce sync.Once
+	file_towards_v1_towards_proto_rawDescData []byte
+)
+
+func file_towards_v1_towards_proto_rawDescGZIP() []byte {
+	file_towards_v1_towards_proto_rawDescOnce.Do(func() {
+		file_towards_v1_towards_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_towards_v1_towards_proto_rawDesc), len(file_towards_v1_towards_proto_rawDesc)))
+	})
+	return file_towards_v1_towards_proto_rawDescData
+}
+
+var file_towards_v1_towards_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_towards_v1_towards_proto_goTypes = []any{
+	(*GetWeatherRequest)(nil),     // 0: towards.v1.GetWeatherRequest
+	(*Weather)(nil),               // 1: towards.v1.Weather
+	(*WeatherMetrics)(nil),        // 2: towards.v1.WeatherMetrics
+	(*ThermalMetrics)(nil),        // 3: towards.v1.ThermalMetrics
+	(*ComfortMetrics)(nil),        // 4: towards.v1.ComfortMetrics
+	(*Hour)(nil),                  // 5: towards.v1.Hour
+	(*CountryMetrics)(nil),        // 6: towards.v1.CountryMetrics
+	(*CityMetrics)(nil),           // 7: towards.v1.CityMetrics
+	(*EarthquakeMetrics)(nil),     // 8: towards.v1.EarthquakeMetrics
+	(*Earthquake)(nil),            // 9: towards.v1.Earthquake
+	(*Alert)(nil),                 // 10: towards.v1.Alert
+	(*Forecast)(nil),              // 11: towards.v1.Forecast
+	(*CompareCitiesRequest)(nil),  // 12: towards.v1.CompareCitiesRequest
+	(*CompareCitiesResponse)(nil), // 13: towards.v1.CompareCitiesResponse
+	(*CityResult)(nil),            // 14: towards.v1.CityResult
+	(*AskRequest)(nil),            // 15: towards.v1.AskRequest
+	(*AskChunk)(nil),              // 16: towards.v1.AskChunk
+	(*ImproveRequest)(nil),        // 17: towards.v1.ImproveRequest
+	(*ImproveResponse)(nil),       // 18: towards.v1.ImproveResponse
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 20: google.protobuf.Struct
+}
+var file_towards_v1_towards_proto_depIdxs = []int32{
+	2,  // 0: towards.v1.Weather.weather:type_name -> towards.v1.WeatherMetrics
+	3,  // 1: towards.v1.Weather.thermal:type_name -> towards.v1.ThermalMetrics
+	4,  // 2: towards.v1.Weather.comfort:type_name -> towards.v1.ComfortMetrics
+	5,  // 3: towards.v1.Weather.hours:type_name -> towards.v1.Hour
+	6,  // 4: towards.v1.Weather.country:type_name -> towards.v1.CountryMetrics
+	7,  // 5: towards.v1.Weather.city_stats:type_name -> towards.v1.CityMetrics
+	8,  // 6: towards.v1.Weather.earthquakes:type_name -> towards.v1.EarthquakeMetrics
+	10, // 7: towards.v1.Weather.alerts:type_name -> towards.v1.Alert
+	11, // 8: towards.v1.Weather.forecast:type_name -> towards.v1.Forecast
+	9,  // 9: towards.v1.EarthquakeMetrics.recent:type_name -> towards.v1.Earthquake
+	19, // 10: towards.v1.Earthquake.time:type_name -> google.protobuf.Timestamp
+	14, // 11: towards.v1.CompareCitiesResponse.results:type_name -> towards.v1.CityResult
+	1,  // 12: towards.v1.CityResult.weather:type_name -> towards.v1.Weather
+	20, // 13: towards.v1.ImproveRequest.metrics:type_name -> google.protobuf.Struct
+	0,  // 14: towards.v1.Towards.GetWeather:input_type -> towards.v1.GetWeatherRequest
+	12, // 15: towards.v1.Towards.CompareCities:input_type -> towards.v1.CompareCitiesRequest
+	15, // 16: towards.v1.Towards.Ask:input_type -> towards.v1.AskRequest
+	17, // 17: towards.v1.Towards.Improve:input_type -> towards.v1.ImproveRequest
+	1,  // 18: towards.v1.Towards.GetWeather:output_type -> towards.v1.Weather
+	13, // 19: towards.v1.Towards.CompareCities:output_type -> towards.v1.CompareCitiesResponse
+	16, // 20: towards.v1.Towards.Ask:output_type -> towards.v1.AskChunk
+	18, // 21: towards.v1.Towards.Improve:output_type -> towards.v1.ImproveResponse
+	18, // [18:22] is the sub-list for method output_type
+	14, // [14:18] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_towards_v1_towards_proto_init() }
+func file_towards_v1_towards_proto_init() {
+	if File_towards_v1_towards_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_towards_v1_towards_proto_rawDesc), len(file_towards_v1_towards_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_towards_v1_towards_proto_goTypes,
+		DependencyIndexes: file_towards_v1_towards_proto_depIdxs,
+		MessageInfos:      file_towards_v1_towards_proto_msgTypes,
+	}.Build()
+	File_towards_v1_towards_proto = out.File
+	file_towards_v1_towards_proto_goTypes = nil
+	file_towards_v1_towards_proto_depIdxs = nil
+}
@@ -0,0 +1,259 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: towards/v1/towards.proto
+
+package towardsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Towards_GetWeather_FullMethodName    = "/towards.v1.Towards/GetWeather"
+	Towards_CompareCities_FullMethodName = "/towards.v1.Towards/CompareCities"
+	Towards_Ask_FullMethodName           = "/towards.v1.Towards/Ask"
+	Towards_Improve_FullMethodName       = "/towards.v1.Towards/Improve"
+)
+
+// TowardsClient is the client API for Towards service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Towards exposes the city metrics and AI endpoints of the REST API.
+// Authenticate with the same API keys, sent as "x-api-key" or
+// "authorization: Bearer <key>" metadata. Failures carry a
+// google.rpc.ErrorInfo detail whose reason is the REST error code.
+type TowardsClient interface {
+	// GetWeather mirrors GET /v2/weather.
+	GetWeather(ctx context.Context, in *GetWeatherRequest, opts ...grpc.CallOption) (*Weather, error)
+	// CompareCities fetches several cities concurrently and ranks them by
+	// comfort index. A city that fails does not fail the call.
+	CompareCities(ctx context.Context, in *CompareCitiesRequest, opts ...grpc.CallOption) (*CompareCitiesResponse, error)
+	// Ask streams the assistant's reply as it is generated.
+	Ask(ctx context.Context, in *AskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AskChunk], error)
+	// Improve mirrors POST /v1/improve.
+	Improve(ctx context.Context, in *ImproveRequest, opts ...grpc.CallOption) (*ImproveResponse, error)
+}
+
+type towardsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTowardsClient(cc grpc.ClientConnInterface) TowardsClient {
+	return &towardsClient{cc}
+}
+
+func (c *towardsClient) GetWeather(ctx context.Context, in *GetWeatherRequest, opts ...grpc.CallOption) (*Weather, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Weather)
+	err := c.cc.Invoke(ctx, Towards_GetWeather_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *towardsClient) CompareCities(ctx context.Context, in *CompareCitiesRequest, opts ...grpc.CallOption) (*CompareCitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompareCitiesResponse)
+	err := c.cc.Invoke(ctx, Towards_CompareCities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *towardsClient) Ask(ctx context.Context, in *AskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AskChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Towards_ServiceDesc.Streams[0], Towards_Ask_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AskRequest, AskChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Towards_AskClient = grpc.ServerStreamingClient[AskChunk]
+
+func (c *towardsClient) Improve(ctx context.Context, in *ImproveRequest, opts ...grpc.CallOption) (*ImproveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImproveResponse)
+	err := c.cc.Invoke(ctx, Towards_Improve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TowardsServer is the server API for Towards service.
+// All implementations must embed UnimplementedTowardsServer
+// for forward compatibility.
+//
+// Towards exposes the city metrics and AI endpoints of the REST API.
+// Authenticate with the same API keys, sent as "x-api-key" or
+// "authorization: Bearer <key>" metadata. Failures carry a
+// google.rpc.ErrorInfo detail whose reason is the REST error code.
+type TowardsServer interface {
+	// GetWeather mirrors GET /v2/weather.
+	GetWeather(context.Context, *GetWeatherRequest) (*Weather, error)
+	// CompareCities fetches several cities concurrently and ranks them by
+	// comfort index. A city that fails does not fail the call.
+	CompareCities(context.Context, *CompareCitiesRequest) (*CompareCitiesResponse, error)
+	// Ask streams the assistant's reply as it is generated.
+	Ask(*AskRequest, grpc.ServerStreamingServer[AskChunk]) error
+	// Improve mirrors POST /v1/improve.
+	Improve(context.Context, *ImproveRequest) (*ImproveResponse, error)
+	mustEmbedUnimplementedTowardsServer()
+}
+
+// UnimplementedTowardsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTowardsServer struct{}
+
+func (UnimplementedTowardsServer) GetWeather(context.Context, *GetWeatherRequest) (*Weather, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWeather not implemented")
+}
+func (UnimplementedTowardsServer) CompareCities(context.Context, *CompareCitiesRequest) (*CompareCitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareCities not implemented")
+}
+func (UnimplementedTowardsServer) Ask(*AskRequest, grpc.ServerStreamingServer[AskChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Ask not implemented")
+}
+func (UnimplementedTowardsServer) Improve(context.Context, *ImproveRequest) (*ImproveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Improve not implemented")
+}
+func (UnimplementedTowardsServer) mustEmbedUnimplementedTowardsServer() {}
+func (UnimplementedTowardsServer) testEmbeddedByValue()                 {}
+
+// UnsafeTowardsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TowardsServer will
+// result in compilation errors.
+type UnsafeTowardsServer interface {
+	mustEmbedUnimplementedTowardsServer()
+}
+
+func RegisterTowardsServer(s grpc.ServiceRegistrar, srv TowardsServer) {
+	// If the following call pancis, it indicates UnimplementedTowardsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Towards_ServiceDesc, srv)
+}
+
+func _Towards_GetWeather_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWeatherRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TowardsServer).GetWeather(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Towards_GetWeather_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TowardsServer).GetWeather(ctx, req.(*GetWeatherRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Towards_CompareCities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareCitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TowardsServer).CompareCities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Towards_CompareCities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TowardsServer).CompareCities(ctx, req.(*CompareCitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Towards_Ask_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AskRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TowardsServer).Ask(m, &grpc.GenericServerStream[AskRequest, AskChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Towards_AskServer = grpc.ServerStreamingServer[AskChunk]
+
+func _Towards_Improve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImproveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TowardsServer).Improve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Towards_Improve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TowardsServer).Improve(ctx, req.(*ImproveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Towards_ServiceDesc is the grpc.ServiceDesc for Towards service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Towards_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "towards.v1.Towards",
+	HandlerType: (*TowardsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetWeather",
+			Handler:    _Towards_GetWeather_Handler,
+		},
+		{
+			MethodName: "CompareCities",
+			Handler:    _Towards_CompareCities_Handler,
+		},
+		{
+			MethodName: "Improve",
+			Handler:    _Towards_Improve_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Ask",
+			Handler:       _Towards_Ask_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "towards/v1/towards.proto",
+}
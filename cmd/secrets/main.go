@@ -0,0 +1,66 @@
+// Command secrets manages the encrypted secrets file read when SECRETS_FILE is
+// set.
+//
+//	secrets keygen
+//	SECRETS_KEY=... secrets encrypt < secrets.json > secrets.enc
+//	SECRETS_KEY=... secrets decrypt < secrets.enc
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/publicthrone547/towards_project/internal/secrets"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		key, err := secrets.NewKey()
+		check(err)
+		fmt.Println(key)
+	case "encrypt":
+		var values map[string]string
+		check(json.NewDecoder(os.Stdin).Decode(&values))
+		out, err := secrets.Encrypt(requireKey(), values)
+		check(err)
+		os.Stdout.Write(out)
+	case "decrypt":
+		data, err := io.ReadAll(os.Stdin)
+		check(err)
+		values, err := secrets.Decrypt(requireKey(), data)
+		check(err)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		check(enc.Encode(values))
+	default:
+		usage()
+	}
+}
+
+func requireKey() string {
+	key := os.Getenv("SECRETS_KEY")
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "SECRETS_KEY must be set")
+		os.Exit(1)
+	}
+	return key
+}
+
+func check(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: secrets keygen | encrypt | decrypt")
+	os.Exit(2)
+}
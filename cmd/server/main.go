@@ -1,26 +1,178 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/cache"
 	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/db"
+	"github.com/publicthrone547/towards_project/internal/grpcapi"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/health"
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/metrics"
+	"github.com/publicthrone547/towards_project/internal/middleware"
+	"github.com/publicthrone547/towards_project/internal/ratelimit"
 	"github.com/publicthrone547/towards_project/internal/routes"
+	"github.com/publicthrone547/towards_project/internal/subscriptions"
+	"github.com/publicthrone547/towards_project/internal/tracing"
+	"github.com/publicthrone547/towards_project/internal/worker"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	r := gin.Default()
-	cfg := config.Load()
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := db.MustConnect(cfg.Database.URL)
+	defer database.Close()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("init tracing: %v", err)
+	}
+
+	m := metrics.New()
+	providers := handlers.UpstreamProviders(cfg)
+	// Retries wrap the instrumentation so every attempt gets its own span and
+	// is counted separately.
+	client := &http.Client{Transport: httpclient.NewTransport(
+		m.Transport(tracing.Transport(handlers.UpstreamTransport(cfg, http.DefaultTransport), providers, handlers.TracePropagation(cfg)), providers),
+		handlers.OutboundOptions(cfg),
+		handlers.Upstreams(cfg),
+	)}
+	if cfg.Outbound.Mode != "live" {
+		log.Warnf("upstream mode %s: fixtures in %s", cfg.Outbound.Mode, cfg.Outbound.FixturesDir)
+	}
+	c := cache.New(24 * time.Hour)
+	m.RegisterCache(c)
+	srv, err := handlers.NewServer(cfg, database, client, c, ai.NewClient(cfg.AI, client, m))
+	if err != nil {
+		log.Fatalf("init server: %v", err)
+	}
+
+	// ctx is cancelled on SIGINT/SIGTERM and stops the background loops.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler := subscriptions.NewScheduler(srv.Subscriptions(), srv.EvaluateCity, cfg.Subscriptions.CheckInterval)
+	go scheduler.Run(ctx)
+
+	if len(cfg.Worker.Cities) > 0 {
+		refresher := worker.NewRefresher(srv.Snapshots(), srv.CollectSnapshot, cfg.Worker.Cities, cfg.Worker.Interval)
+		go refresher.Run(ctx)
+	}
+
+	r := gin.New()
+	// gin trusts every proxy by default, which would let clients pick their
+	// own IP, and rate limit bucket, with X-Forwarded-For.
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("server.trusted_proxies: %v", err)
+	}
+	r.Use(gin.CustomRecovery(apierror.Recovery), tracing.Middleware(), logging.Middleware(), m.Middleware())
+	r.Use(middleware.CORS(cfg.CORS))
+
+	var limiter ratelimit.Limiter
+	if cfg.RateLimit.Backend == "postgres" {
+		pl := ratelimit.NewPostgresLimiter(database)
+		go pl.Cleanup(ctx, 10*time.Minute, time.Hour)
+		limiter = pl
+	} else {
+		ml := ratelimit.NewMemoryLimiter()
+		go ml.Cleanup(ctx, 10*time.Minute, time.Hour)
+		limiter = ml
+	}
+	r.Use(ratelimit.Middleware(limiter, cfg.RateLimit.Policy))
+
+	if !cfg.Auth.Enabled {
+		log.Warn("auth.enabled=false: API routes are public")
+	}
+
+	authenticator := auth.New(srv.APIKeys(), cfg.Auth.Enabled)
+	checker := health.NewChecker(cfg.Server.HealthCacheTTL, 5*time.Second, srv.HealthChecks()...)
+	if err := routes.Register(r, srv, authenticator, checker, m); err != nil {
+		log.Fatalf("register routes: %v", err)
+	}
+
+	httpServer := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		log.Infof("Listening on %s", httpServer.Addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("grpc listen: %v", err)
+		}
+		grpcServer = grpcapi.NewServer(srv, authenticator, limiter, cfg.RateLimit.Policy)
+		go func() {
+			log.Infof("gRPC listening on %s", lis.Addr())
+			serveErr <- grpcServer.Serve(lis)
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server: %v", err)
 		}
-		c.Next()
-	})
+	case <-ctx.Done():
+	}
+	stop()
 
-	routes.Register(r)
+	log.Infof("Shutting down, draining requests for up to %s", cfg.Server.ShutdownTimeout)
+	checker.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("graceful shutdown: %v", err)
+	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Errorf("flush traces: %v", err)
+	}
+	log.Info("Server stopped")
+}
 
-	r.Run(":" + cfg.Port)
-}
\ No newline at end of file
+// stopGRPC waits for in-flight RPCs until ctx expires, then cancels them.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Errorf("graceful gRPC shutdown: %v", ctx.Err())
+		s.Stop()
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/cache"
+	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+)
+
+// backend is where commands get their data: the service layer in-process or
+// a remote server.
+type backend interface {
+	weather(ctx context.Context, city, date string) (*handlers.WeatherResponseV2, error)
+	compare(ctx context.Context, cities []string, date string) ([]comparison, error)
+	earthquakes(ctx context.Context, city string) (*handlers.EarthquakeMetrics, error)
+	// ask passes the reply to fn as it arrives; remote replies arrive whole.
+	ask(ctx context.Context, prompt string, fn func(chunk string) error) error
+}
+
+// comparison is one row of "towards compare".
+type comparison struct {
+	City        string  `json:"city"`
+	Conditions  string  `json:"conditions,omitempty"`
+	Temperature float64 `json:"temperature"`
+	Comfort     float64 `json:"comfort_index"`
+	AirPurity   int     `json:"air_purity"`
+	RoadTraffic int     `json:"road_traffic"`
+	CrimeRisks  int     `json:"crime_risks"`
+	Error       string  `json:"error,omitempty"`
+}
+
+type local struct {
+	s *handlers.Server
+}
+
+func newLocal(configFile string) (*local, error) {
+	var args []string
+	if configFile != "" {
+		args = []string{"-config", configFile}
+	}
+	cfg, err := config.LoadStandalone(args)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: httpclient.NewTransport(handlers.UpstreamTransport(cfg, http.DefaultTransport), handlers.OutboundOptions(cfg), handlers.Upstreams(cfg))}
+	s, err := handlers.NewServer(cfg, nil, client, cache.New(time.Hour), ai.NewClient(cfg.AI, client, nil))
+	if err != nil {
+		return nil, err
+	}
+	return &local{s: s}, nil
+}
+
+func (l *local) weather(ctx context.Context, city, date string) (*handlers.WeatherResponseV2, error) {
+	w, err := l.s.Weather(ctx, city, date)
+	if err != nil {
+		return nil, err
+	}
+	return handlers.WeatherV2(w), nil
+}
+
+func (l *local) compare(ctx context.Context, cities []string, date string) ([]comparison, error) {
+	results, err := l.s.CompareCities(ctx, cities, date)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]comparison, 0, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			rows = append(rows, comparison{City: r.City, Error: errorText(r.Err)})
+			continue
+		}
+		w := r.Weather
+		rows = append(rows, comparison{
+			City:        r.City,
+			Conditions:  w.Conditions,
+			Temperature: w.Temperature,
+			Comfort:     w.LifeComfortIdx,
+			AirPurity:   w.AirPurity,
+			RoadTraffic: w.RoadTraffic,
+			CrimeRisks:  w.CrimeRisks,
+		})
+	}
+	return rows, nil
+}
+
+func (l *local) earthquakes(ctx context.Context, city string) (*handlers.EarthquakeMetrics, error) {
+	return l.s.Earthquakes(ctx, city)
+}
+
+func (l *local) ask(ctx context.Context, prompt string, fn func(string) error) error {
+	return l.s.AskStream(ctx, "", prompt, fn)
+}
+
+// errorText renders an API error the way problem documents expose it.
+func errorText(err error) string {
+	e := apierror.From(err)
+	if e.Detail == "" {
+		return string(e.Code)
+	}
+	return string(e.Code) + ": " + e.Detail
+}
+
+// remote talks to a running server: REST for weather and ask, GraphQL where
+// selecting fields avoids upstream calls (comparisons skip the AI forecast,
+// earthquakes skip the country and city lookups).
+type remote struct {
+	base   string
+	apiKey string
+	http   *http.Client
+}
+
+func newRemote(base, apiKey string) *remote {
+	return &remote{base: strings.TrimRight(base, "/"), apiKey: apiKey, http: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// do sends a JSON request and decodes a 2xx response into out. Problem
+// responses become errors carrying their code and detail.
+func (r *remote) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.base+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.apiKey != "" {
+		req.Header.Set("X-API-Key", r.apiKey)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var p apierror.Problem
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil || p.Code == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		if p.Detail == "" {
+			return fmt.Errorf("%s", p.Code)
+		}
+		return fmt.Errorf("%s: %s", p.Code, p.Detail)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *remote) weather(ctx context.Context, city, date string) (*handlers.WeatherResponseV2, error) {
+	q := url.Values{"city": {city}}
+	if date != "" {
+		q.Set("date", date)
+	}
+	var w handlers.WeatherResponseV2
+	if err := r.do(ctx, http.MethodGet, "/v2/weather?"+q.Encode(), nil, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+type graphQLResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []handlers.GraphQLError    `json:"errors"`
+}
+
+// fieldError returns the message of the first error under the top-level
+// field alias, if any.
+func (g *graphQLResult) fieldError(alias string) string {
+	for _, e := range g.Errors {
+		if len(e.Path) > 0 && e.Path[0] == alias {
+			if code, ok := e.Extensions["code"].(string); ok {
+				return code + ": " + e.Message
+			}
+			return e.Message
+		}
+	}
+	return ""
+}
+
+func (r *remote) graphql(ctx context.Context, query string, vars map[string]interface{}) (*graphQLResult, error) {
+	var res graphQLResult
+	if err := r.do(ctx, http.MethodPost, "/graphql", handlers.GraphQLRequest{Query: query, Variables: vars}, &res); err != nil {
+		return nil, err
+	}
+	if res.Data == nil && len(res.Errors) > 0 {
+		return nil, fmt.Errorf("graphql: %s", res.Errors[0].Message)
+	}
+	return &res, nil
+}
+
+func (r *remote) compare(ctx context.Context, cities []string, date string) ([]comparison, error) {
+	var params, fields []string
+	vars := map[string]interface{}{"date": date}
+	for i, city := range cities {
+		name := fmt.Sprintf("c%d", i)
+		vars[name] = city
+		params = append(params, "$"+name+": String!")
+		fields = append(fields, name+": city(name: $"+name+", date: $date) { ...row }")
+	}
+	query := "query Compare(" + strings.Join(params, ", ") + ", $date: String) { " + strings.Join(fields, " ") + " }\n" +
+		"fragment row on City { conditions weather { temperature } comfort { index } airPurity roadTraffic crimeRisks }"
+	res, err := r.graphql(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]comparison, 0, len(cities))
+	for i, city := range cities {
+		alias := fmt.Sprintf("c%d", i)
+		row := comparison{City: city}
+		if msg := res.fieldError(alias); msg != "" {
+			row.Error = msg
+			rows = append(rows, row)
+			continue
+		}
+		var c struct {
+			Conditions string `json:"conditions"`
+			Weather    struct {
+				Temperature float64 `json:"temperature"`
+			} `json:"weather"`
+			Comfort struct {
+				Index float64 `json:"index"`
+			} `json:"comfort"`
+			AirPurity   int `json:"airPurity"`
+			RoadTraffic int `json:"roadTraffic"`
+			CrimeRisks  int `json:"crimeRisks"`
+		}
+		if err := json.Unmarshal(res.Data[alias], &c); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", city, err)
+		}
+		row.Conditions = c.Conditions
+		row.Temperature = c.Weather.Temperature
+		row.Comfort = c.Comfort.Index
+		row.AirPurity = c.AirPurity
+		row.RoadTraffic = c.RoadTraffic
+		row.CrimeRisks = c.CrimeRisks
+		rows = append(rows, row)
+	}
+	// Same order as the in-process comparison.
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if (a.Error == "") != (b.Error == "") {
+			return a.Error == ""
+		}
+		return a.Error == "" && a.Comfort > b.Comfort
+	})
+	return rows, nil
+}
+
+func (r *remote) earthquakes(ctx context.Context, city string) (*handlers.EarthquakeMetrics, error) {
+	const query = `query Quakes($name: String!) { city(name: $name) { earthquakes { risk count maxMag maxMag7d recent { time magnitude place } } } }`
+	res, err := r.graphql(ctx, query, map[string]interface{}{"name": city})
+	if err != nil {
+		return nil, err
+	}
+	if msg := res.fieldError("city"); msg != "" {
+		return nil, fmt.Errorf("%s", msg)
+	}
+	var c struct {
+		Earthquakes *struct {
+			Risk     float64               `json:"risk"`
+			Count    int                   `json:"count"`
+			MaxMag   float64               `json:"maxMag"`
+			MaxMag7d float64               `json:"maxMag7d"`
+			Recent   []handlers.Earthquake `json:"recent"`
+		} `json:"earthquakes"`
+	}
+	if err := json.Unmarshal(res.Data["city"], &c); err != nil {
+		return nil, err
+	}
+	if c.Earthquakes == nil {
+		return nil, nil
+	}
+	q := c.Earthquakes
+	return &handlers.EarthquakeMetrics{Risk: q.Risk, Count: q.Count, MaxMag: q.MaxMag, MaxMag7d: q.MaxMag7d, Recent: q.Recent}, nil
+}
+
+func (r *remote) ask(ctx context.Context, prompt string, fn func(string) error) error {
+	var resp handlers.AskResponse
+	if err := r.do(ctx, http.MethodPost, "/v1/ask", handlers.AskRequest{Prompt: prompt}, &resp); err != nil {
+		return err
+	}
+	return fn(resp.Reply)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxTurns is how many previous exchanges are replayed to the assistant,
+// which is otherwise stateless.
+const maxTurns = 6
+
+type turn struct {
+	question, answer string
+}
+
+// chat runs an interactive session until EOF or /quit. Each question is sent
+// with the recent conversation so follow-ups keep their context.
+func chat(ctx context.Context, b backend, in io.Reader, out io.Writer) error {
+	fmt.Fprintln(out, "Ask how to make your city better. /reset forgets the conversation, /quit leaves.")
+	sc := bufio.NewScanner(in)
+	var history []turn
+	for {
+		fmt.Fprint(out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		switch line {
+		case "":
+			continue
+		case "/quit", "/exit":
+			return nil
+		case "/reset":
+			history = nil
+			fmt.Fprintln(out, "Conversation cleared.")
+			continue
+		}
+
+		var reply strings.Builder
+		err := b.ask(ctx, transcript(history, line), func(chunk string) error {
+			reply.WriteString(chunk)
+			_, err := io.WriteString(out, chunk)
+			return err
+		})
+		fmt.Fprintln(out)
+		if err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+			continue
+		}
+		history = append(history, turn{line, strings.TrimSpace(reply.String())})
+		if len(history) > maxTurns {
+			history = history[len(history)-maxTurns:]
+		}
+	}
+}
+
+func transcript(history []turn, question string) string {
+	if len(history) == 0 {
+		return question
+	}
+	var sb strings.Builder
+	sb.WriteString("Conversation so far:\n")
+	for _, t := range history {
+		fmt.Fprintf(&sb, "User: %s\nAssistant: %s\n", t.question, t.answer)
+	}
+	sb.WriteString("\nNew question: ")
+	sb.WriteString(question)
+	return sb.String()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const usage = `Usage: towards <command> [flags] [args]
+
+Commands:
+  weather <city>          weather, comfort and enrichments for a city
+  compare <city> <city>…  rank cities by comfort index
+  quakes <city>           earthquake summary around a city
+  ask                     interactive chat with the city improvement assistant
+
+By default commands run the service layer in-process using the server's
+configuration (.env, CONFIG_FILE, environment); Postgres is not needed. Pass
+-server or set TOWARDS_SERVER to query a running server instead.
+
+Run "towards <command> -h" for the command's flags.
+`
+
+// options are the flags shared by every command.
+type options struct {
+	server     string
+	apiKey     string
+	configFile string
+	json       bool
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.server, "server", os.Getenv("TOWARDS_SERVER"), "base URL of a towards server, e.g. http://localhost:3001; in-process when empty")
+	fs.StringVar(&o.apiKey, "api-key", os.Getenv("TOWARDS_API_KEY"), "API key for -server")
+	fs.StringVar(&o.configFile, "config", "", "YAML or TOML config file for in-process mode")
+	fs.BoolVar(&o.json, "json", false, "print JSON instead of a table")
+}
+
+func (o *options) backend() (backend, error) {
+	if o.server != "" {
+		return newRemote(o.server, o.apiKey), nil
+	}
+	return newLocal(o.configFile)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	// In-process mode logs through logrus; keep enrichment warnings but not
+	// the per-request info lines unless asked for.
+	if os.Getenv("LOG_LEVEL") == "" {
+		os.Setenv("LOG_LEVEL", "warn")
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	var err error
+	switch cmd {
+	case "weather":
+		err = runWeather(args)
+	case "compare":
+		err = runCompare(args)
+	case "quakes", "earthquakes":
+		err = runQuakes(args)
+	case "ask":
+		err = runAsk(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.SetOutput(os.Stderr)
+		fmt.Fprintln(os.Stderr, "towards:", err)
+		os.Exit(1)
+	}
+}
+
+// parse parses a command's flags and checks its positional argument count.
+func parse(fs *flag.FlagSet, args []string, minArgs int, argsUsage string) ([]string, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: towards %s [flags] %s\n", fs.Name(), argsUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < minArgs {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return fs.Args(), nil
+}
+
+func runWeather(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("weather", flag.ExitOnError)
+	o.register(fs)
+	date := fs.String("date", "", "day to report, DD-MM-YYYY or YYYY-MM-DD; today when empty")
+	rest, err := parse(fs, args, 1, "<city>")
+	if err != nil {
+		return err
+	}
+	b, err := o.backend()
+	if err != nil {
+		return err
+	}
+	w, err := b.weather(context.Background(), strings.Join(rest, " "), *date)
+	if err != nil {
+		return err
+	}
+	if o.json {
+		return printJSON(w)
+	}
+	return printWeather(os.Stdout, w)
+}
+
+func runCompare(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	o.register(fs)
+	date := fs.String("date", "", "day to compare, DD-MM-YYYY or YYYY-MM-DD; today when empty")
+	cities, err := parse(fs, args, 2, "<city> <city>...")
+	if err != nil {
+		return err
+	}
+	b, err := o.backend()
+	if err != nil {
+		return err
+	}
+	rows, err := b.compare(context.Background(), cities, *date)
+	if err != nil {
+		return err
+	}
+	if o.json {
+		return printJSON(rows)
+	}
+	return printComparison(os.Stdout, rows)
+}
+
+func runQuakes(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("quakes", flag.ExitOnError)
+	o.register(fs)
+	rest, err := parse(fs, args, 1, "<city>")
+	if err != nil {
+		return err
+	}
+	b, err := o.backend()
+	if err != nil {
+		return err
+	}
+	city := strings.Join(rest, " ")
+	q, err := b.earthquakes(context.Background(), city)
+	if err != nil {
+		return err
+	}
+	if o.json {
+		return printJSON(q)
+	}
+	return printQuakes(os.Stdout, city, q)
+}
+
+func runAsk(args []string) error {
+	var o options
+	fs := flag.NewFlagSet("ask", flag.ExitOnError)
+	o.register(fs)
+	if _, err := parse(fs, args, 0, ""); err != nil {
+		return err
+	}
+	b, err := o.backend()
+	if err != nil {
+		return err
+	}
+	return chat(context.Background(), b, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/publicthrone547/towards_project/internal/handlers"
+)
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printWeather(out io.Writer, w *handlers.WeatherResponseV2) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	row := func(label, format string, args ...interface{}) {
+		fmt.Fprintf(tw, "%s\t%s\n", label, fmt.Sprintf(format, args...))
+	}
+
+	row("City", "%s", w.City)
+	row("Date", "%s", w.Date)
+	row("Conditions", "%s", w.Conditions)
+	row("Temperature", "%.1f °C (min %.1f, max %.1f)", w.Weather.Temperature, w.Weather.TempMin, w.Weather.TempMax)
+	row("Feels like", "%.1f °C (UTCI %.1f)", w.Thermal.FeelsLike, w.Thermal.UTCI)
+	row("Humidity", "%.0f%%", w.Weather.Humidity)
+	row("Wind", "%.1f km/h", w.Weather.WindSpeed)
+	row("Comfort index", "%.1f", w.Comfort.Index)
+	row("Air / traffic / crime", "%d / %d / %d", w.Comfort.AirPurity, w.Comfort.RoadTraffic, w.Comfort.CrimeRisks)
+	if len(w.Comfort.BestHours) > 0 {
+		row("Best hours", "%s", strings.Join(w.Comfort.BestHours, ", "))
+	}
+	if c := w.Country; c != nil {
+		row("Country", "GDP $%.0f, population %d, %.1f/km²", c.GDPUSD, c.Population, c.PopulationDensity)
+	}
+	if c := w.CityStats; c != nil {
+		row("City population", "%d", c.Population)
+	}
+	if q := w.Earthquakes; q != nil {
+		row("Earthquake risk", "%.1f (%d events, max M%.1f)", q.Risk, q.Count, q.MaxMag)
+	}
+	for _, a := range w.Alerts {
+		row("Alert", "[%s] %s", a.Severity, a.Title)
+	}
+	if f := w.Forecast; f != nil {
+		if f.ErrorCode != "" {
+			row("Forecast", "unavailable (%s)", f.ErrorCode)
+		} else {
+			row("Forecast", "%s", strings.TrimSpace(f.Text))
+		}
+	}
+	return tw.Flush()
+}
+
+func printComparison(out io.Writer, rows []comparison) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CITY\tCOMFORT\tTEMP °C\tCONDITIONS\tAIR\tTRAFFIC\tCRIME")
+	for _, r := range rows {
+		if r.Error != "" {
+			fmt.Fprintf(tw, "%s\t-\t-\t%s\t\t\t\n", r.City, r.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%.1f\t%.1f\t%s\t%d\t%d\t%d\n",
+			r.City, r.Comfort, r.Temperature, r.Conditions, r.AirPurity, r.RoadTraffic, r.CrimeRisks)
+	}
+	return tw.Flush()
+}
+
+func printQuakes(out io.Writer, city string, q *handlers.EarthquakeMetrics) error {
+	if q == nil {
+		_, err := fmt.Fprintf(out, "No location found for %s\n", city)
+		return err
+	}
+	fmt.Fprintf(out, "%s: risk %.1f/100, %d events ≥ M3 within 100 km in 30 years, max M%.1f, max M%.1f in the last 7 days\n",
+		city, q.Risk, q.Count, q.MaxMag, q.MaxMag7d)
+	if len(q.Recent) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME (UTC)\tMAG\tPLACE")
+	for _, e := range q.Recent {
+		fmt.Fprintf(tw, "%s\t%.1f\t%s\n", e.Time.UTC().Format("2006-01-02 15:04"), e.Magnitude, e.Place)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrEmptyResponse is returned when Gemini answers without any candidate text.
+var ErrEmptyResponse = errors.New("gemini returned no text")
+
+// APIError is a non-200 or error-payload response from Gemini.
+type APIError struct {
+	HTTPStatus int
+	Status     string // Google RPC status, e.g. RESOURCE_EXHAUSTED
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("gemini %d %s: %s", e.HTTPStatus, e.Status, e.Message)
+	}
+	return fmt.Sprintf("gemini %d: %s", e.HTTPStatus, e.Message)
+}
+
+// RateLimited reports whether Gemini rejected the call for quota reasons.
+func (e *APIError) RateLimited() bool {
+	return e.HTTPStatus == http.StatusTooManyRequests || e.Status == "RESOURCE_EXHAUSTED"
+}
+
+// BlockedError reports that safety filters blocked the prompt or the answer.
+type BlockedError struct {
+	Reason string // blockReason or finishReason, e.g. SAFETY
+	Prompt bool   // the prompt itself was blocked
+}
+
+func (e *BlockedError) Error() string {
+	if e.Prompt {
+		return "gemini blocked the prompt: " + e.Reason
+	}
+	return "gemini blocked the response: " + e.Reason
+}
+
+// blockingFinishReasons are the finish reasons that mean the answer was
+// withheld rather than merely cut short.
+var blockingFinishReasons = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+}
@@ -1,12 +1,19 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/logging"
 )
 
 type geminiResponse struct {
@@ -16,66 +23,261 @@ type geminiResponse struct {
 				Text string `json:"text"`
 			} `json:"parts"`
 		} `json:"content"`
+		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// Usage is the token accounting reported by the model for one call.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Recorder receives one observation per generate call. outcome is "ok",
+// "blocked", "empty" (no candidate text) or "error".
+type Recorder interface {
+	RecordLLM(model, outcome string, duration time.Duration, usage Usage)
 }
 
 // Default embedded system instruction used when no instruction is provided.
 var DefaultInstruction = `You are an AI assistant for a city improvement chat. Your goal is to help participants come up with ideas and provide advice on how to make the city better — improving quality of life, environment, infrastructure, safety, and public services. Respond in a friendly, clear, and constructive way. Encourage positive discussions, suggest practical solutions, global best practices, and modern technologies that can be applied locally. Avoid political topics or conflicts. Your main purpose is to inspire residents to collaborate and make their city a better place.`
 
-func AskGemini(apiKey, instruction, promt string) (string, error) {
+// Client calls the Gemini generateContent API.
+type Client struct {
+	apiKey string
+	model  string
+	models string // URL prefix of the model endpoints
+	http   *http.Client
+	rec    Recorder
+}
+
+// NewClient returns a client using httpClient, which is expected to apply
+// cfg.Timeout to the Gemini host. rec may be nil.
+func NewClient(cfg config.AIConfig, httpClient *http.Client, rec Recorder) *Client {
+	return &Client{
+		apiKey: cfg.APIKey,
+		model:  cfg.Model,
+		models: strings.TrimRight(cfg.BaseURL, "/") + "/models/",
+		http:   httpClient,
+		rec:    rec,
+	}
+}
+
+// Configured reports whether the client has an API key.
+func (c *Client) Configured() bool {
+	return c != nil && c.apiKey != ""
+}
+
+// Ask sends a chat prompt prefixed with instruction (DefaultInstruction when
+// empty) as a single text part.
+func (c *Client) Ask(ctx context.Context, instruction, promt string) (string, error) {
 	if instruction == "" {
 		instruction = DefaultInstruction
-		log.Info("Using embedded default instruction")
+		logging.FromContext(ctx).Info("Using embedded default instruction")
 	} else {
-		log.Info("Using provided instruction")
+		logging.FromContext(ctx).Info("Using provided instruction")
+	}
+
+	return c.generate(ctx, instruction+"\n"+"не пиши что ты понял и т.п, переходи к делу\n"+promt)
+}
+
+// Generate sends each of parts as a separate text part and returns the first
+// candidate's text.
+func (c *Client) Generate(ctx context.Context, parts ...string) (string, error) {
+	return c.generate(ctx, parts...)
+}
+
+// AskStream is Ask with the reply delivered incrementally: fn is called with
+// each text chunk as the model produces it. It returns the errors Ask would,
+// or fn's error, which stops the stream.
+func (c *Client) AskStream(ctx context.Context, instruction, promt string, fn func(chunk string) error) error {
+	if instruction == "" {
+		instruction = DefaultInstruction
+	}
+	return c.stream(ctx, fn, instruction+"\n"+"не пиши что ты понял и т.п, переходи к делу\n"+promt)
+}
+
+// generate returns the first candidate's text. Error payloads become
+// *APIError, safety blocks *BlockedError and a response without text
+// ErrEmptyResponse.
+func (c *Client) generate(ctx context.Context, parts ...string) (text string, err error) {
+	start := time.Now()
+	var usage Usage
+	defer func() { c.record(start, usage, err) }()
+
+	resp, err := c.post(ctx, ":generateContent", parts)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	logging.FromContext(ctx).WithField("model", c.model).Debugf("Gemini response: %s, %d bytes", resp.Status, len(body))
+
+	var res geminiResponse
+	jsonErr := json.Unmarshal(body, &res)
+	if err := responseError(resp, res, jsonErr); err != nil {
+		return "", err
+	}
+	if jsonErr != nil {
+		return "", fmt.Errorf("decode gemini response: %w", jsonErr)
+	}
+	usage = res.usage()
+
+	text, err = res.text()
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", ErrEmptyResponse
 	}
-	url := "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
+	return text, nil
+}
+
+// stream calls streamGenerateContent, which sends one geminiResponse per
+// server-sent event. Usage is cumulative, so the last event's counts are kept.
+func (c *Client) stream(ctx context.Context, fn func(string) error, parts ...string) (err error) {
+	start := time.Now()
+	var usage Usage
+	defer func() { c.record(start, usage, err) }()
+
+	resp, err := c.post(ctx, ":streamGenerateContent?alt=sse", parts)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var res geminiResponse
+		return responseError(resp, res, json.Unmarshal(body, &res))
+	}
+
+	sent := false
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var res geminiResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &res); err != nil {
+			return fmt.Errorf("decode gemini stream event: %w", err)
+		}
+		if err := responseError(resp, res, nil); err != nil {
+			return err
+		}
+		usage = res.usage()
+		text, err := res.text()
+		if err != nil {
+			return err
+		}
+		if text == "" {
+			continue
+		}
+		if err := fn(text); err != nil {
+			return err
+		}
+		sent = true
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if !sent {
+		return ErrEmptyResponse
+	}
+	return nil
+}
+
+// post sends parts as a single content to the model endpoint with suffix.
+func (c *Client) post(ctx context.Context, suffix string, parts []string) (*http.Response, error) {
+	textParts := make([]map[string]string, 0, len(parts))
+	for _, p := range parts {
+		textParts = append(textParts, map[string]string{"text": p})
+	}
 	reqBody := map[string]interface{}{
 		"contents": []map[string]interface{}{
-			{
-				"parts": []map[string]string{
-					{"text": instruction + "\n" + "не пиши что ты понял и т.п, переходи к делу\n" + promt},
-				},
-			},
+			{"parts": textParts},
 		},
 	}
 
 	data, _ := json.Marshal(reqBody)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.models+c.model+suffix, bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-goog-api-key", apiKey)
+	req.Header.Set("X-goog-api-key", c.apiKey)
+	return c.http.Do(req)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+func (c *Client) record(start time.Time, usage Usage, err error) {
+	if c.rec == nil {
+		return
 	}
-	defer resp.Body.Close()
+	outcome := "ok"
+	var blocked *BlockedError
+	switch {
+	case errors.As(err, &blocked):
+		outcome = "blocked"
+	case errors.Is(err, ErrEmptyResponse):
+		outcome = "empty"
+	case err != nil:
+		outcome = "error"
+	}
+	c.rec.RecordLLM(c.model, outcome, time.Since(start), usage)
+}
 
-	body, _ := io.ReadAll(resp.Body)
-	log.Infof("Gemini raw response: %s", string(body))
-
-	var res struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-	}
-	if err := json.Unmarshal(body, &res); err != nil {
-		return "", err
+// responseError returns an *APIError for a non-200 status or an error
+// payload. jsonErr is the result of decoding res.
+func responseError(resp *http.Response, res geminiResponse, jsonErr error) error {
+	if resp.StatusCode == http.StatusOK && res.Error == nil {
+		return nil
 	}
+	apiErr := &APIError{HTTPStatus: resp.StatusCode, Message: resp.Status}
+	if jsonErr == nil && res.Error != nil {
+		apiErr.Status = res.Error.Status
+		apiErr.Message = res.Error.Message
+	}
+	return apiErr
+}
+
+func (r *geminiResponse) usage() Usage {
+	return Usage{
+		PromptTokens:     r.UsageMetadata.PromptTokenCount,
+		CompletionTokens: r.UsageMetadata.CandidatesTokenCount,
+	}
+}
 
-	if len(res.Candidates) > 0 && len(res.Candidates[0].Content.Parts) > 0 {
-		return res.Candidates[0].Content.Parts[0].Text, nil
+// text returns the first candidate's text, which is empty when the response
+// has none, or a *BlockedError for a blocked prompt or candidate.
+func (r *geminiResponse) text() (string, error) {
+	if reason := r.PromptFeedback.BlockReason; reason != "" {
+		return "", &BlockedError{Reason: reason, Prompt: true}
+	}
+	if len(r.Candidates) == 0 {
+		return "", nil
+	}
+	cand := r.Candidates[0]
+	if len(cand.Content.Parts) > 0 && cand.Content.Parts[0].Text != "" {
+		return cand.Content.Parts[0].Text, nil
+	}
+	if blockingFinishReasons[cand.FinishReason] {
+		return "", &BlockedError{Reason: cand.FinishReason}
 	}
-	// fallback: return raw body as string so callers can see what the API returned
-	return string(body), nil
+	return "", nil
 }
@@ -0,0 +1,189 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeveritySevere  Severity = "severe"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeveritySevere:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+// Metric names understood by the default rules. Rules may reference any key
+// present in the Metrics map passed to Evaluate.
+const (
+	MetricTemperature    = "temperature"
+	MetricFeelsLike      = "feels_like"
+	MetricUTCI           = "utci"
+	MetricWindSpeedMs    = "wind_speed_ms"
+	MetricHumidity       = "humidity"
+	MetricAirPurity      = "air_purity"
+	MetricComfortIndex   = "life_comfort_index"
+	MetricQuakeMaxMag7d  = "earthquake_max_mag_7d"
+	MetricEarthquakeRisk = "earthquake_risk"
+)
+
+type Rule struct {
+	ID        string   `json:"id"`
+	Metric    string   `json:"metric"`
+	Op        string   `json:"op"`
+	Threshold float64  `json:"threshold"`
+	Severity  Severity `json:"severity"`
+	Title     string   `json:"title"`
+	Guidance  string   `json:"guidance"`
+}
+
+type Alert struct {
+	ID        string   `json:"id"`
+	Severity  Severity `json:"severity"`
+	Title     string   `json:"title"`
+	Guidance  string   `json:"guidance"`
+	Metric    string   `json:"metric"`
+	Value     float64  `json:"value"`
+	Threshold float64  `json:"threshold"`
+}
+
+// Metrics maps metric names to observed values. Missing metrics never trigger.
+type Metrics map[string]float64
+
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Evaluate returns every alert whose rule matches, most severe first. The
+// result is never nil so it always serialises as a JSON array.
+func (e *Engine) Evaluate(m Metrics) []Alert {
+	out := []Alert{}
+	for _, r := range e.rules {
+		v, ok := m[r.Metric]
+		if !ok || !compare(v, r.Op, r.Threshold) {
+			continue
+		}
+		out = append(out, Alert{
+			ID:        r.ID,
+			Severity:  r.Severity,
+			Title:     r.Title,
+			Guidance:  r.Guidance,
+			Metric:    r.Metric,
+			Value:     v,
+			Threshold: r.Threshold,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Severity.rank() > out[j].Severity.rank()
+	})
+	return out
+}
+
+var validOps = map[string]bool{">": true, ">=": true, "<": true, "<=": true, "==": true}
+
+func compare(v float64, op string, threshold float64) bool {
+	switch op {
+	case ">":
+		return v > threshold
+	case ">=":
+		return v >= threshold
+	case "<":
+		return v < threshold
+	case "<=":
+		return v <= threshold
+	case "==":
+		return v == threshold
+	}
+	return false
+}
+
+// LoadRules reads a JSON array of rules from path.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for i, r := range rules {
+		if r.ID == "" || r.Metric == "" {
+			return nil, fmt.Errorf("rule %d: id and metric are required", i)
+		}
+		if !validOps[r.Op] {
+			return nil, fmt.Errorf("rule %s: unsupported op %q", r.ID, r.Op)
+		}
+		switch r.Severity {
+		case SeverityInfo, SeverityWarning, SeveritySevere:
+		default:
+			return nil, fmt.Errorf("rule %s: unsupported severity %q", r.ID, r.Severity)
+		}
+	}
+	return rules, nil
+}
+
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			ID: "extreme-heat", Metric: MetricTemperature, Op: ">", Threshold: 35, Severity: SeveritySevere,
+			Title:    "Extreme heat",
+			Guidance: "Avoid outdoor activity between 11:00 and 17:00, drink water regularly and check on elderly neighbours.",
+		},
+		{
+			ID: "heat-stress", Metric: MetricUTCI, Op: ">", Threshold: 32, Severity: SeverityWarning,
+			Title:    "Strong heat stress",
+			Guidance: "Stay in the shade, wear light clothing and limit physical exertion.",
+		},
+		{
+			ID: "extreme-cold", Metric: MetricFeelsLike, Op: "<", Threshold: -20, Severity: SeveritySevere,
+			Title:    "Extreme cold",
+			Guidance: "Limit time outdoors and cover exposed skin to avoid frostbite.",
+		},
+		{
+			ID: "high-wind", Metric: MetricWindSpeedMs, Op: ">", Threshold: 20, Severity: SeveritySevere,
+			Title:    "Storm-force wind",
+			Guidance: "Secure loose objects, stay away from trees and avoid unnecessary travel.",
+		},
+		{
+			ID: "unhealthy-air", Metric: MetricAirPurity, Op: "<", Threshold: 30, Severity: SeverityWarning,
+			Title:    "Unhealthy air quality",
+			Guidance: "Sensitive groups should reduce prolonged outdoor exertion; keep windows closed.",
+		},
+		{
+			ID: "recent-earthquake", Metric: MetricQuakeMaxMag7d, Op: ">=", Threshold: 5, Severity: SeveritySevere,
+			Title:    "Strong earthquake in the last 7 days",
+			Guidance: "Expect aftershocks. Review your emergency plan and follow local authority instructions.",
+		},
+		{
+			ID: "low-comfort", Metric: MetricComfortIndex, Op: "<", Threshold: 25, Severity: SeverityInfo,
+			Title:    "Low life comfort",
+			Guidance: "Conditions are uncomfortable today; plan indoor activities.",
+		},
+	}
+}
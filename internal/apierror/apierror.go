@@ -0,0 +1,159 @@
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Code is a stable identifier clients can switch on; errors are rendered as
+// RFC 7807 problem documents carrying it. Codes are never renamed;
+// the human-readable detail may change freely.
+type Code string
+
+const (
+	CodeInvalidRequest   Code = "INVALID_REQUEST"
+	CodeMissingParameter Code = "MISSING_PARAMETER"
+	CodeInvalidDate      Code = "INVALID_DATE"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodeMethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	CodeCityNotFound     Code = "CITY_NOT_FOUND"
+	CodeCountryNotFound  Code = "COUNTRY_NOT_FOUND"
+
+	CodeAPIKeyRequired   Code = "API_KEY_REQUIRED"
+	CodeAPIKeyInvalid    Code = "API_KEY_INVALID"
+	CodeAPIKeyRevoked    Code = "API_KEY_REVOKED"
+	CodeAdminTokenNeeded Code = "ADMIN_TOKEN_REQUIRED"
+	CodeOriginNotAllowed Code = "ORIGIN_NOT_ALLOWED"
+	CodeRateLimited      Code = "RATE_LIMITED"
+	CodeQuotaExceeded    Code = "QUOTA_EXCEEDED"
+
+	CodeNotConfigured Code = "FEATURE_NOT_CONFIGURED"
+	CodeInternal      Code = "INTERNAL_ERROR"
+
+	CodeUpstreamWeatherUnavailable Code = "UPSTREAM_WEATHER_UNAVAILABLE"
+	CodeUpstreamWeatherInvalid     Code = "UPSTREAM_WEATHER_INVALID_RESPONSE"
+	CodeUpstreamTimeout            Code = "UPSTREAM_TIMEOUT"
+	CodeUpstreamAIUnavailable      Code = "UPSTREAM_AI_UNAVAILABLE"
+	CodeUpstreamAIRateLimited      Code = "UPSTREAM_AI_RATE_LIMITED"
+	CodeAIContentBlocked           Code = "AI_CONTENT_BLOCKED"
+	CodeAIEmptyResponse            Code = "AI_EMPTY_RESPONSE"
+	CodeUpstreamCountryUnavailable Code = "UPSTREAM_COUNTRY_UNAVAILABLE"
+)
+
+type codeInfo struct {
+	status int
+	title  string
+}
+
+var codes = map[Code]codeInfo{
+	CodeInvalidRequest:   {http.StatusBadRequest, "Invalid request"},
+	CodeMissingParameter: {http.StatusBadRequest, "Missing parameter"},
+	CodeInvalidDate:      {http.StatusBadRequest, "Invalid date"},
+	CodeNotFound:         {http.StatusNotFound, "Not found"},
+	CodeMethodNotAllowed: {http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeCityNotFound:     {http.StatusNotFound, "City not found"},
+	CodeCountryNotFound:  {http.StatusNotFound, "Country not found"},
+
+	CodeAPIKeyRequired:   {http.StatusUnauthorized, "API key required"},
+	CodeAPIKeyInvalid:    {http.StatusUnauthorized, "Invalid API key"},
+	CodeAPIKeyRevoked:    {http.StatusForbidden, "API key revoked"},
+	CodeAdminTokenNeeded: {http.StatusUnauthorized, "Admin token required"},
+	CodeOriginNotAllowed: {http.StatusForbidden, "Origin not allowed"},
+	CodeRateLimited:      {http.StatusTooManyRequests, "Rate limit exceeded"},
+	CodeQuotaExceeded:    {http.StatusTooManyRequests, "Quota exceeded"},
+
+	CodeNotConfigured: {http.StatusServiceUnavailable, "Feature not configured"},
+	CodeInternal:      {http.StatusInternalServerError, "Internal error"},
+
+	CodeUpstreamWeatherUnavailable: {http.StatusBadGateway, "Weather provider unavailable"},
+	CodeUpstreamWeatherInvalid:     {http.StatusBadGateway, "Invalid weather provider response"},
+	CodeUpstreamTimeout:            {http.StatusGatewayTimeout, "Upstream timeout"},
+	CodeUpstreamAIUnavailable:      {http.StatusBadGateway, "AI provider unavailable"},
+	CodeUpstreamAIRateLimited:      {http.StatusServiceUnavailable, "AI provider rate limited"},
+	CodeAIContentBlocked:           {http.StatusUnprocessableEntity, "Content blocked by AI safety filters"},
+	CodeAIEmptyResponse:            {http.StatusBadGateway, "Empty AI response"},
+	CodeUpstreamCountryUnavailable: {http.StatusBadGateway, "Country data provider unavailable"},
+}
+
+// Codes returns every defined code with its HTTP status, for documentation.
+func Codes() map[Code]int {
+	out := make(map[Code]int, len(codes))
+	for c, info := range codes {
+		out[c] = info.status
+	}
+	return out
+}
+
+// Status returns the HTTP status for code, 500 for unknown codes.
+func (c Code) Status() int {
+	if info, ok := codes[c]; ok {
+		return info.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title returns the short summary shared by all problems with code.
+func (c Code) Title() string {
+	if info, ok := codes[c]; ok {
+		return info.title
+	}
+	return http.StatusText(c.Status())
+}
+
+// Type is the problem type URI for code.
+func (c Code) Type() string {
+	return "urn:towards:problem:" + strings.ToLower(strings.ReplaceAll(string(c), "_", "-"))
+}
+
+// Error is an API error with a stable code. Err, when set, is the internal
+// cause: it is logged but only exposed through Detail.
+type Error struct {
+	Code   Code
+	Status int // overrides Code.Status() when non-zero
+	Detail string
+	Err    error
+	// Extensions are extra members added to the problem document.
+	Extensions map[string]interface{}
+}
+
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Wrap returns an Error for code caused by err.
+func Wrap(code Code, err error, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Detail: fmt.Sprintf(format, args...), Err: err}
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus returns the response status for e.
+func (e *Error) HTTPStatus() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	return e.Code.Status()
+}
+
+// With adds a problem extension member and returns e.
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]interface{}{}
+	}
+	e.Extensions[key] = value
+	return e
+}
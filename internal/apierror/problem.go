@@ -0,0 +1,100 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/secrets"
+)
+
+// ContentType is the RFC 7807 media type.
+const ContentType = "application/problem+json"
+
+// Problem is the RFC 7807 response body. Code and RequestID are extension
+// members.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      Code   `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON inlines the extension members next to the standard ones.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	data, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	m := map[string]interface{}{}
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// From converts any error to an *Error; errors without a code become
+// INTERNAL_ERROR.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: CodeInternal, Err: err}
+}
+
+// ProblemFor builds the response body for err in the context of c. Details
+// are passed through secrets.Redact since causes often embed upstream URLs.
+func ProblemFor(c *gin.Context, err error) Problem {
+	e := From(err)
+	return Problem{
+		Type:       e.Code.Type(),
+		Title:      e.Code.Title(),
+		Status:     e.HTTPStatus(),
+		Detail:     secrets.Redact(e.Detail),
+		Instance:   c.Request.URL.Path,
+		Code:       e.Code,
+		RequestID:  logging.RequestID(c),
+		Extensions: e.Extensions,
+	}
+}
+
+// Respond writes err as a problem document. Server-side failures are logged
+// with their cause.
+func Respond(c *gin.Context, err error) {
+	p := ProblemFor(c, err)
+	if p.Status >= 500 {
+		logging.FromContext(c.Request.Context()).WithField("code", p.Code).Errorf("%v", secrets.Redact(err.Error()))
+	}
+	c.Render(p.Status, problemRender{p})
+}
+
+// Abort is Respond for middleware: it also stops the handler chain.
+func Abort(c *gin.Context, err error) {
+	Respond(c, err)
+	c.Abort()
+}
+
+// NoRoute and NoMethod render gin's 404 and 405 as problems.
+func NoRoute(c *gin.Context) {
+	Respond(c, New(CodeNotFound, "no route for %s %s", c.Request.Method, c.Request.URL.Path))
+}
+
+func NoMethod(c *gin.Context) {
+	Respond(c, New(CodeMethodNotAllowed, "method %s not allowed on %s", c.Request.Method, c.Request.URL.Path))
+}
+
+// Recovery renders recovered panics as INTERNAL_ERROR, for gin.CustomRecovery.
+func Recovery(c *gin.Context, recovered interface{}) {
+	Abort(c, New(CodeInternal, "internal error"))
+}
@@ -0,0 +1,20 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// problemRender is a gin render.Render writing application/problem+json.
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Quota kinds counted per key.
+const (
+	KindAI      = "ai"
+	KindWeather = "weather"
+)
+
+const contextKey = "api_key"
+
+type Authenticator struct {
+	store   *Store
+	enabled bool
+}
+
+// New returns an Authenticator. When enabled is false every middleware it
+// produces lets requests through untouched.
+func New(store *Store, enabled bool) *Authenticator {
+	return &Authenticator{store: store, enabled: enabled}
+}
+
+// Enabled reports whether API keys are enforced.
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Quota is the state of a key's daily quota after a call was counted. Limit
+// is 0 for unlimited keys.
+type Quota struct {
+	Limit int
+	Used  int
+}
+
+// Check validates the raw key and, when kind is not empty, counts the call
+// against the key's daily quota for kind. Failures are *apierror.Error.
+// Callers skip Check when the authenticator is disabled.
+func (a *Authenticator) Check(ctx context.Context, raw, kind string) (*models.APIKey, Quota, error) {
+	if a.store == nil {
+		return nil, Quota{}, apierror.New(apierror.CodeNotConfigured, "api keys are not configured")
+	}
+	if raw == "" {
+		return nil, Quota{}, apierror.New(apierror.CodeAPIKeyRequired, "send the key as X-API-Key or Authorization: Bearer")
+	}
+
+	key, err := a.store.FindByKey(ctx, raw)
+	if err == ErrNotFound {
+		return nil, Quota{}, apierror.New(apierror.CodeAPIKeyInvalid, "invalid api key")
+	}
+	if err != nil {
+		return nil, Quota{}, apierror.Wrap(apierror.CodeInternal, err, "failed to verify api key")
+	}
+	if key.RevokedAt != nil {
+		return nil, Quota{}, apierror.New(apierror.CodeAPIKeyRevoked, "api key revoked")
+	}
+	if kind == "" {
+		return key, Quota{}, nil
+	}
+	q, err := a.consume(ctx, key, kind)
+	return key, q, err
+}
+
+// consume counts one call of kind against key's daily quota.
+func (a *Authenticator) consume(ctx context.Context, key *models.APIKey, kind string) (Quota, error) {
+	q := Quota{Limit: quotaFor(key, kind)}
+	used, ok, err := a.store.Consume(ctx, key.ID, kind, q.Limit)
+	if err != nil {
+		log.Errorf("record api key usage: %v", err)
+	}
+	q.Used = used
+	if err == nil && !ok {
+		return q, apierror.New(apierror.CodeQuotaExceeded, "daily %s quota exceeded", kind).With("quota_kind", kind)
+	}
+	return q, nil
+}
+
+type chargeKey struct{}
+
+type charger struct {
+	a   *Authenticator
+	key *models.APIKey
+}
+
+// WithKey returns a copy of ctx carrying key, so work deeper in the call can
+// Charge it for more quota kinds than the one its route is counted as.
+func (a *Authenticator) WithKey(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, chargeKey{}, charger{a: a, key: key})
+}
+
+// Charge counts one call of kind against the quota of the key in ctx, e.g.
+// an AI forecast generated for a weather request. It does nothing when ctx
+// carries no key, as with auth disabled. Failures are *apierror.Error.
+func Charge(ctx context.Context, kind string) error {
+	ch, ok := ctx.Value(chargeKey{}).(charger)
+	if !ok {
+		return nil
+	}
+	_, err := ch.a.consume(ctx, ch.key, kind)
+	return err
+}
+
+// Require rejects requests without a valid, unrevoked API key. When kind is
+// not empty the call is also counted against the key's daily quota for kind.
+func (a *Authenticator) Require(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.enabled {
+			c.Next()
+			return
+		}
+
+		key, q, err := a.Check(c.Request.Context(), KeyFromRequest(c.Request), kind)
+		if q.Limit > 0 {
+			c.Header("X-Quota-Limit", strconv.Itoa(q.Limit))
+			c.Header("X-Quota-Remaining", strconv.Itoa(max(q.Limit-q.Used, 0)))
+		}
+		if err != nil {
+			apierror.Abort(c, err)
+			return
+		}
+
+		c.Set(contextKey, key)
+		c.Request = c.Request.WithContext(a.WithKey(c.Request.Context(), key))
+		c.Next()
+	}
+}
+
+// KeyFromContext returns the API key that authenticated the request, if any.
+func KeyFromContext(c *gin.Context) *models.APIKey {
+	if v, ok := c.Get(contextKey); ok {
+		if k, ok := v.(*models.APIKey); ok {
+			return k
+		}
+	}
+	return nil
+}
+
+// Admin guards admin endpoints with a static token sent as
+// "Authorization: Bearer <token>" or X-Admin-Token.
+func Admin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			apierror.Abort(c, apierror.New(apierror.CodeNotConfigured, "admin api disabled"))
+			return
+		}
+		got := c.GetHeader("X-Admin-Token")
+		if got == "" {
+			got = bearer(c.GetHeader("Authorization"))
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			apierror.Abort(c, apierror.New(apierror.CodeAdminTokenNeeded, "admin token required"))
+			return
+		}
+		c.Next()
+	}
+}
+
+func quotaFor(k *models.APIKey, kind string) int {
+	switch kind {
+	case KindAI:
+		return k.AIQuota
+	case KindWeather:
+		return k.WeatherQuota
+	}
+	return 0
+}
+
+// KeyFromRequest returns the raw API key sent as X-API-Key or a bearer token.
+func KeyFromRequest(r *http.Request) string {
+	return Key(r.Header.Get("X-API-Key"), r.Header.Get("Authorization"))
+}
+
+// Key picks the raw API key from the values of the X-API-Key and
+// Authorization headers, preferring the former.
+func Key(apiKey, authorization string) string {
+	if apiKey != "" {
+		return apiKey
+	}
+	return bearer(authorization)
+}
+
+func bearer(h string) string {
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
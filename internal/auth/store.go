@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+var ErrNotFound = errors.New("api key not found")
+
+const keyPrefix = "tw_"
+
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+// GenerateKey returns a new random plaintext key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create stores a new key and returns its plaintext value, which is not
+// recoverable afterwards.
+func (s *Store) Create(ctx context.Context, k *models.APIKey) (string, error) {
+	plain, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	k.KeyHash = HashKey(plain)
+	k.KeyPrefix = plain[:len(keyPrefix)+8]
+
+	const q = `INSERT INTO api_keys (name, key_prefix, key_hash, ai_quota, weather_quota)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	if err := s.db.QueryRowxContext(ctx, q, k.Name, k.KeyPrefix, k.KeyHash, k.AIQuota, k.WeatherQuota).
+		Scan(&k.ID, &k.CreatedAt); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (s *Store) FindByKey(ctx context.Context, key string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := s.db.GetContext(ctx, &k, `SELECT * FROM api_keys WHERE key_hash = $1`, HashKey(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (s *Store) List(ctx context.Context) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := s.db.SelectContext(ctx, &keys, `SELECT * FROM api_keys ORDER BY id`)
+	return keys, err
+}
+
+func (s *Store) Revoke(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Consume counts one call of kind against today's usage for key. When quota
+// is positive and already reached, nothing is counted and ok is false.
+func (s *Store) Consume(ctx context.Context, keyID int64, kind string, quota int) (used int, ok bool, err error) {
+	const q = `INSERT INTO api_key_usage (key_id, day, kind, count)
+		VALUES ($1, (NOW() AT TIME ZONE 'UTC')::date, $2, 1)
+		ON CONFLICT (key_id, day, kind) DO UPDATE SET count = api_key_usage.count + 1
+		WHERE $3 <= 0 OR api_key_usage.count < $3
+		RETURNING count`
+	err = s.db.QueryRowxContext(ctx, q, keyID, kind, quota).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return quota, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, keyID); err != nil {
+		return used, true, err
+	}
+	return used, true, nil
+}
+
+func (s *Store) Usage(ctx context.Context, keyID int64, days int) ([]models.APIKeyUsage, error) {
+	out := []models.APIKeyUsage{}
+	err := s.db.SelectContext(ctx, &out,
+		`SELECT * FROM api_key_usage
+		WHERE key_id = $1 AND day > (NOW() AT TIME ZONE 'UTC')::date - $2::int
+		ORDER BY day DESC, kind`,
+		keyID, days)
+	return out, err
+}
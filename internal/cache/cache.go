@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// Stats counts lookups for one key namespace.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Cache is a concurrency-safe in-memory key/value store whose entries expire
+// after a fixed TTL.
+type Cache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]entry
+	stats map[string]*Stats
+}
+
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, items: map[string]entry{}, stats: map[string]*Stats{}}
+}
+
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.statsLocked(key)
+	e, ok := c.items[key]
+	if !ok {
+		st.Misses++
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.items, key)
+		st.Misses++
+		return nil, false
+	}
+	st.Hits++
+	return e.value, true
+}
+
+// statsLocked returns the counters for key's namespace, the part before the
+// first ':' ("country:fr" counts under "country").
+func (c *Cache) statsLocked(key string) *Stats {
+	ns, _, ok := strings.Cut(key, ":")
+	if !ok {
+		ns = "default"
+	}
+	st, ok := c.stats[ns]
+	if !ok {
+		st = &Stats{}
+		c.stats[ns] = st
+	}
+	return st
+}
+
+// Stats returns a copy of the lookup counters per key namespace.
+func (c *Cache) Stats() map[string]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]Stats, len(c.stats))
+	for ns, st := range c.stats {
+		out[ns] = *st
+	}
+	return out
+}
+
+// Len returns the number of stored entries, including expired ones that have
+// not been purged yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// purgeThreshold is the size above which Set drops expired entries.
+const purgeThreshold = 1024
+
+func (c *Cache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.items) >= purgeThreshold {
+		c.purgeLocked()
+	}
+	c.items[key] = entry{value: value, expires: time.Now().Add(c.ttl)}
+}
+
+func (c *Cache) purgeLocked() {
+	now := time.Now()
+	for k, e := range c.items {
+		if now.After(e.expires) {
+			delete(c.items, k)
+		}
+	}
+}
@@ -1,51 +1,266 @@
 package config
 
 import (
-	"os"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/ratelimit"
+	"github.com/publicthrone547/towards_project/internal/secrets"
 	log "github.com/sirupsen/logrus"
 )
 
+// Config is the complete service configuration. It is loaded once at startup
+// by Load and passed to the components that need it.
 type Config struct {
-	DatabaseURL   string
-	Port          string
-	GeminiAPIKey string
+	Server        ServerConfig
+	API           APIConfig
+	Log           LogConfig
+	Tracing       TracingConfig
+	Database      DatabaseConfig
+	Auth          AuthConfig
+	RateLimit     RateLimitConfig
+	CORS          CORSConfig
+	Outbound      OutboundConfig
+	Weather       UpstreamConfig
+	AI            AIConfig
+	Geocoding     UpstreamConfig
+	USGS          UpstreamConfig
+	Countries     UpstreamConfig
+	WorldBank     UpstreamConfig
+	Alerts        AlertsConfig
+	Subscriptions SubscriptionsConfig
+	Worker        WorkerConfig
 }
 
-func Load() *Config {
+type ServerConfig struct {
+	Port            string
+	GRPCPort        string // empty disables the gRPC server
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	// HealthCacheTTL is how long /readyz reuses the last check results.
+	HealthCacheTTL time.Duration
+	// TrustedProxies are the IPs or CIDRs of reverse proxies whose
+	// X-Forwarded-For header names the client. Empty trusts none, and the
+	// client IP is the connection's remote address.
+	TrustedProxies []string
+}
+
+// APIConfig controls the unversioned routes kept as deprecated aliases of /v1.
+type APIConfig struct {
+	LegacyRoutes       bool
+	LegacyDeprecatedAt time.Time // sent as the Deprecation header
+	LegacySunset       time.Time // sent as the Sunset header; zero omits it
+}
+
+type LogConfig struct {
+	Format string // text or json
+	Level  string
+}
+
+type TracingConfig struct {
+	Exporter    string // none, stdout or otlp
+	Endpoint    string // OTLP/HTTP endpoint URL; empty uses the OTEL_EXPORTER_OTLP_* defaults
+	ServiceName string
+}
+
+type DatabaseConfig struct {
+	URL string
+}
+
+type AuthConfig struct {
+	Enabled    bool
+	AdminToken string
+}
+
+type RateLimitConfig struct {
+	Backend string
+	Policy  ratelimit.Policy
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// OutboundConfig tunes the shared client used for every upstream call.
+type OutboundConfig struct {
+	Retries          int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int // consecutive failures that open a breaker; 0 disables
+	BreakerCooldown  time.Duration
+	// Timeout applies to hosts without their own section.
+	Timeout time.Duration
+	// Mode is live, record (live, saving every response under FixturesDir)
+	// or replay (serve the saved responses, no network).
+	Mode        string
+	FixturesDir string
+	// FixturesFallback lets replay answer a request without an exact
+	// fixture with one recorded for the same method and path, for demos.
+	FixturesFallback bool
+}
+
+// UpstreamConfig holds the settings shared by outbound API integrations.
+type UpstreamConfig struct {
+	APIKey string
+	// BaseURL is the root the integration's endpoints are resolved against;
+	// change it to use a mirror, a gateway or a local stand-in.
+	BaseURL string
+	// Headers are added to every request, e.g. credentials for a gateway.
+	Headers   map[string]string
+	Timeout   time.Duration
+	UserAgent string
+	// MinInterval is the minimum spacing between requests; 0 means none.
+	MinInterval time.Duration
+	// PropagateTrace sends the W3C trace context headers. Set it only for
+	// internal services: third parties would see our trace IDs.
+	PropagateTrace bool
+}
+
+type AIConfig struct {
+	APIKey  string
+	Model   string
+	BaseURL string // API root; models/<model>:generateContent is appended
+	Headers map[string]string
+	Timeout time.Duration
+	// PropagateTrace is as in UpstreamConfig, e.g. for an internal gateway.
+	PropagateTrace bool
+}
 
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp: true,
-		ForceColors:   true,
-	})
+type AlertsConfig struct {
+	RulesFile string
+}
+
+type SubscriptionsConfig struct {
+	CheckInterval time.Duration
+}
+
+type WorkerConfig struct {
+	Cities   []string
+	Interval time.Duration
+}
+
+// Default returns the configuration used when no source overrides a value.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "3001",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    90 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+			HealthCacheTTL:  15 * time.Second,
+		},
+		API: APIConfig{
+			LegacyRoutes:       true,
+			LegacyDeprecatedAt: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+			LegacySunset:       time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+		},
+		Log:     LogConfig{Format: "text", Level: "info"},
+		Tracing: TracingConfig{Exporter: "none", ServiceName: "towards"},
+		Auth:    AuthConfig{Enabled: true},
+		RateLimit: RateLimitConfig{
+			Backend: "memory",
+			Policy: ratelimit.Policy{
+				IP:     ratelimit.Limit{Rate: 1, Burst: 60},
+				Key:    ratelimit.Limit{Rate: 2, Burst: 120},
+				Routes: map[string]ratelimit.Limit{},
+			},
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Origin", "Content-Type", "Authorization", "X-API-Key"},
+			ExposedHeaders: []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "X-Request-ID", "Deprecation", "Sunset", "Link"},
+			MaxAge:         10 * time.Minute,
+		},
+		Outbound: OutboundConfig{
+			Retries:          2,
+			RetryBaseDelay:   200 * time.Millisecond,
+			RetryMaxDelay:    5 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+			Timeout:          15 * time.Second,
+			Mode:             "live",
+			FixturesDir:      "testdata/fixtures",
+		},
+		Weather: UpstreamConfig{BaseURL: "https://weather.visualcrossing.com/VisualCrossingWebServices/rest/services/", Timeout: 15 * time.Second},
+		AI:      AIConfig{Model: "gemini-2.0-flash", BaseURL: "https://generativelanguage.googleapis.com/v1beta/", Timeout: 30 * time.Second},
+		// Nominatim's usage policy allows at most one request per second.
+		Geocoding:     UpstreamConfig{BaseURL: "https://nominatim.openstreetmap.org/", Timeout: 10 * time.Second, UserAgent: "towards_project/1.0", MinInterval: time.Second},
+		USGS:          UpstreamConfig{BaseURL: "https://earthquake.usgs.gov/fdsnws/event/1/", Timeout: 15 * time.Second},
+		Countries:     UpstreamConfig{BaseURL: "https://restcountries.com/v3.1/", Timeout: 10 * time.Second},
+		WorldBank:     UpstreamConfig{BaseURL: "https://api.worldbank.org/v2/", Timeout: 10 * time.Second},
+		Subscriptions: SubscriptionsConfig{CheckInterval: 15 * time.Minute},
+		Worker:        WorkerConfig{Interval: 30 * time.Minute},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence: defaults, a
+// YAML or TOML file (-config flag or CONFIG_FILE), environment variables
+// (including a .env file) and command-line flags. Secrets may also come from
+// <NAME>_FILE or an encrypted file, see package secrets. All problems found are
+// reported together in a *ValidationError.
+func Load(args []string) (*Config, error) {
+	return load(args, false)
+}
+
+// LoadStandalone is Load for tools that run the service layer in-process
+// without Postgres, such as the CLI: database.url is optional.
+func LoadStandalone(args []string) (*Config, error) {
+	return load(args, true)
+}
+
+func load(args []string, databaseOptional bool) (*Config, error) {
+	log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	log.AddHook(secrets.LogHook{})
 
 	if err := godotenv.Load(); err != nil {
 		log.Warn(".env not found, using variable environments")
 	}
 
-	cfg := &Config{
-		DatabaseURL:   getEnv("DATABASE_URL", ""),
-		Port:          getEnv("PORT", "3001"),
-		GeminiAPIKey: getEnv("GEMINI_API_KEY", ""),
-	}
+	cfg := Default()
+	l := newLoader(cfg)
+	l.databaseOptional = databaseOptional
 
-	if cfg.DatabaseURL == "" {
-		log.Fatal("DATABASE_URL is not set")
+	flagValues, configFile, err := l.parseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+	if configFile == "" {
+		configFile = getEnv("CONFIG_FILE", "")
+	}
+	if configFile != "" {
+		l.applyFile(configFile)
 	}
+	l.applyEnv()
+	l.applyFlags(flagValues)
 
-	if cfg.GeminiAPIKey == "" {
-		log.Fatal("GEMINI_API_KEY is not set")
+	cfg.validate(l)
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
+	if cfg.Outbound.Mode == "replay" {
+		// Replayed upstreams ignore credentials, but the integrations stay
+		// switched off without a key.
+		if cfg.Weather.APIKey == "" {
+			cfg.Weather.APIKey = "replay"
+		}
+		if cfg.AI.APIKey == "" {
+			cfg.AI.APIKey = "replay"
+		}
+	}
+	if err := logging.Configure(cfg.Log.Format, cfg.Log.Level); err != nil {
+		return nil, err
 	}
 
 	log.Info("Config loaded")
-	return cfg
-
+	return cfg, nil
 }
-
-func getEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
-	}
-	return fallback
-}
\ No newline at end of file
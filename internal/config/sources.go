@@ -0,0 +1,428 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/publicthrone547/towards_project/internal/ratelimit"
+	"github.com/publicthrone547/towards_project/internal/secrets"
+)
+
+// binding ties one setting to its file key, environment variable and flag.
+// Every source is converted to a string and parsed by set, so all sources
+// accept the same syntax.
+type binding struct {
+	key   string // file key and flag name, e.g. "weather.timeout"
+	env   string
+	usage string
+	set   func(string) error
+}
+
+// secretEnv lists variables resolved through the secrets package, which also
+// accepts <NAME>_FILE and the encrypted SECRETS_FILE.
+var secretEnv = map[string]bool{
+	"DATABASE_URL":        true,
+	"ADMIN_TOKEN":         true,
+	"VISUAL_CROSSING_KEY": true,
+	"GEMINI_API_KEY":      true,
+	// Upstream headers usually carry credentials.
+	"WEATHER_HEADERS":   true,
+	"AI_HEADERS":        true,
+	"GEOCODING_HEADERS": true,
+	"USGS_HEADERS":      true,
+	"COUNTRIES_HEADERS": true,
+	"WORLDBANK_HEADERS": true,
+}
+
+type loader struct {
+	bindings         []binding
+	problems         []string
+	databaseOptional bool
+}
+
+func newLoader(cfg *Config) *loader {
+	return &loader{bindings: []binding{
+		{"server.port", "PORT", "HTTP listen port", stringVar(&cfg.Server.Port)},
+		{"server.grpc_port", "GRPC_PORT", "gRPC listen port, empty to disable", stringVar(&cfg.Server.GRPCPort)},
+		{"server.read_timeout", "SERVER_READ_TIMEOUT", "max time to read a request", durationVar(&cfg.Server.ReadTimeout)},
+		{"server.write_timeout", "SERVER_WRITE_TIMEOUT", "max time to write a response", durationVar(&cfg.Server.WriteTimeout)},
+		{"server.idle_timeout", "SERVER_IDLE_TIMEOUT", "keep-alive idle timeout", durationVar(&cfg.Server.IdleTimeout)},
+		{"server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", "time allowed to drain requests on shutdown", durationVar(&cfg.Server.ShutdownTimeout)},
+		{"server.health_cache_ttl", "HEALTH_CACHE_TTL", "how long readiness results are cached", durationVar(&cfg.Server.HealthCacheTTL)},
+		{"server.trusted_proxies", "TRUSTED_PROXIES", "IPs or CIDRs of reverse proxies trusted for X-Forwarded-For, empty for none", listVar(&cfg.Server.TrustedProxies)},
+		{"api.legacy_routes", "API_LEGACY_ROUTES", "serve unversioned routes as deprecated aliases of /v1", boolVar(&cfg.API.LegacyRoutes)},
+		{"api.legacy_deprecated_at", "API_LEGACY_DEPRECATED_AT", "date the unversioned routes were deprecated (YYYY-MM-DD)", dateVar(&cfg.API.LegacyDeprecatedAt)},
+		{"api.legacy_sunset", "API_LEGACY_SUNSET", "date the unversioned routes will be removed (YYYY-MM-DD), empty for none", dateVar(&cfg.API.LegacySunset)},
+		{"log.format", "LOG_FORMAT", "log output format: text or json", stringVar(&cfg.Log.Format)},
+		{"log.level", "LOG_LEVEL", "minimum log level", stringVar(&cfg.Log.Level)},
+		{"tracing.exporter", "OTEL_TRACES_EXPORTER", "trace exporter: none, stdout or otlp", stringVar(&cfg.Tracing.Exporter)},
+		{"tracing.endpoint", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTLP/HTTP traces endpoint URL", stringVar(&cfg.Tracing.Endpoint)},
+		{"tracing.service_name", "OTEL_SERVICE_NAME", "service name reported in traces", stringVar(&cfg.Tracing.ServiceName)},
+		{"database.url", "DATABASE_URL", "Postgres connection string", stringVar(&cfg.Database.URL)},
+
+		{"auth.enabled", "AUTH_ENABLED", "require API keys on public routes", boolVar(&cfg.Auth.Enabled)},
+		{"auth.admin_token", "ADMIN_TOKEN", "token for /admin endpoints", stringVar(&cfg.Auth.AdminToken)},
+
+		{"rate_limit.backend", "RATE_LIMIT_BACKEND", "memory or postgres", stringVar(&cfg.RateLimit.Backend)},
+		{"rate_limit.global", "RATE_LIMIT_GLOBAL", "limit for all traffic, e.g. 1000/m", limitVar(&cfg.RateLimit.Policy.Global)},
+		{"rate_limit.ip", "RATE_LIMIT_IP", "limit per client IP", limitVar(&cfg.RateLimit.Policy.IP)},
+		{"rate_limit.key", "RATE_LIMIT_KEY", "limit per API key", limitVar(&cfg.RateLimit.Policy.Key)},
+		{"rate_limit.routes", "RATE_LIMIT_ROUTES", "per-route limits, e.g. /weather=300/m,/ask=60/m", routeLimitsVar(&cfg.RateLimit.Policy.Routes)},
+
+		{"cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "allowed origins; * and https://*.example.com patterns", listVar(&cfg.CORS.AllowedOrigins)},
+		{"cors.allowed_methods", "CORS_ALLOWED_METHODS", "allowed methods", listVar(&cfg.CORS.AllowedMethods)},
+		{"cors.allowed_headers", "CORS_ALLOWED_HEADERS", "allowed request headers", listVar(&cfg.CORS.AllowedHeaders)},
+		{"cors.exposed_headers", "CORS_EXPOSED_HEADERS", "response headers exposed to browsers", listVar(&cfg.CORS.ExposedHeaders)},
+		{"cors.allow_credentials", "CORS_ALLOW_CREDENTIALS", "allow cookies and credentials", boolVar(&cfg.CORS.AllowCredentials)},
+		{"cors.max_age", "CORS_MAX_AGE", "preflight cache duration", durationVar(&cfg.CORS.MaxAge)},
+
+		{"outbound.retries", "OUTBOUND_RETRIES", "retries after a failed upstream attempt", intVar(&cfg.Outbound.Retries)},
+		{"outbound.retry_base_delay", "OUTBOUND_RETRY_BASE_DELAY", "initial retry backoff", durationVar(&cfg.Outbound.RetryBaseDelay)},
+		{"outbound.retry_max_delay", "OUTBOUND_RETRY_MAX_DELAY", "maximum retry backoff and honored Retry-After", durationVar(&cfg.Outbound.RetryMaxDelay)},
+		{"outbound.breaker_threshold", "OUTBOUND_BREAKER_THRESHOLD", "consecutive failures that open a circuit breaker, 0 disables", intVar(&cfg.Outbound.BreakerThreshold)},
+		{"outbound.breaker_cooldown", "OUTBOUND_BREAKER_COOLDOWN", "how long an open breaker rejects requests", durationVar(&cfg.Outbound.BreakerCooldown)},
+		{"outbound.timeout", "OUTBOUND_TIMEOUT", "attempt timeout for hosts without their own section", durationVar(&cfg.Outbound.Timeout)},
+		{"outbound.mode", "UPSTREAM_MODE", "live, record (save upstream responses) or replay (serve saved responses offline)", stringVar(&cfg.Outbound.Mode)},
+		{"outbound.fixtures_dir", "UPSTREAM_FIXTURES_DIR", "directory of recorded upstream responses", stringVar(&cfg.Outbound.FixturesDir)},
+		{"outbound.fixtures_fallback", "UPSTREAM_FIXTURES_FALLBACK", "replay the same method and path when no exact fixture matches", boolVar(&cfg.Outbound.FixturesFallback)},
+
+		{"weather.api_key", "VISUAL_CROSSING_KEY", "Visual Crossing API key", stringVar(&cfg.Weather.APIKey)},
+		{"weather.timeout", "WEATHER_TIMEOUT", "Visual Crossing request timeout", durationVar(&cfg.Weather.Timeout)},
+		{"weather.base_url", "WEATHER_BASE_URL", "Visual Crossing API root", stringVar(&cfg.Weather.BaseURL)},
+		{"weather.headers", "WEATHER_HEADERS", "extra Visual Crossing request headers, e.g. Authorization=Bearer x", headersVar(&cfg.Weather.Headers)},
+		{"weather.propagate_trace", "WEATHER_PROPAGATE_TRACE", "send trace context headers to Visual Crossing, only for internal services", boolVar(&cfg.Weather.PropagateTrace)},
+
+		{"ai.api_key", "GEMINI_API_KEY", "Gemini API key", stringVar(&cfg.AI.APIKey)},
+		{"ai.model", "GEMINI_MODEL", "Gemini model name", stringVar(&cfg.AI.Model)},
+		{"ai.timeout", "AI_TIMEOUT", "Gemini request timeout", durationVar(&cfg.AI.Timeout)},
+		{"ai.base_url", "AI_BASE_URL", "Gemini API root, e.g. an internal LLM gateway", stringVar(&cfg.AI.BaseURL)},
+		{"ai.headers", "AI_HEADERS", "extra Gemini request headers", headersVar(&cfg.AI.Headers)},
+		{"ai.propagate_trace", "AI_PROPAGATE_TRACE", "send trace context headers to Gemini, only for internal services", boolVar(&cfg.AI.PropagateTrace)},
+
+		{"geocoding.timeout", "GEOCODING_TIMEOUT", "Nominatim request timeout", durationVar(&cfg.Geocoding.Timeout)},
+		{"geocoding.min_interval", "GEOCODING_MIN_INTERVAL", "minimum spacing between Nominatim requests", durationVar(&cfg.Geocoding.MinInterval)},
+		{"geocoding.user_agent", "GEOCODING_USER_AGENT", "User-Agent sent to Nominatim", stringVar(&cfg.Geocoding.UserAgent)},
+		{"geocoding.base_url", "GEOCODING_BASE_URL", "Nominatim root, e.g. a self-hosted instance", stringVar(&cfg.Geocoding.BaseURL)},
+		{"geocoding.headers", "GEOCODING_HEADERS", "extra Nominatim request headers", headersVar(&cfg.Geocoding.Headers)},
+		{"geocoding.propagate_trace", "GEOCODING_PROPAGATE_TRACE", "send trace context headers to Nominatim, only for internal services", boolVar(&cfg.Geocoding.PropagateTrace)},
+
+		{"usgs.timeout", "USGS_TIMEOUT", "USGS earthquake API timeout", durationVar(&cfg.USGS.Timeout)},
+		{"usgs.base_url", "USGS_BASE_URL", "USGS FDSN event service root", stringVar(&cfg.USGS.BaseURL)},
+		{"usgs.headers", "USGS_HEADERS", "extra USGS request headers", headersVar(&cfg.USGS.Headers)},
+		{"usgs.propagate_trace", "USGS_PROPAGATE_TRACE", "send trace context headers to USGS, only for internal services", boolVar(&cfg.USGS.PropagateTrace)},
+
+		{"countries.timeout", "COUNTRIES_TIMEOUT", "restcountries timeout", durationVar(&cfg.Countries.Timeout)},
+		{"countries.base_url", "COUNTRIES_BASE_URL", "restcountries API root", stringVar(&cfg.Countries.BaseURL)},
+		{"countries.headers", "COUNTRIES_HEADERS", "extra restcountries request headers", headersVar(&cfg.Countries.Headers)},
+		{"countries.propagate_trace", "COUNTRIES_PROPAGATE_TRACE", "send trace context headers to restcountries, only for internal services", boolVar(&cfg.Countries.PropagateTrace)},
+
+		{"worldbank.timeout", "WORLDBANK_TIMEOUT", "World Bank API timeout", durationVar(&cfg.WorldBank.Timeout)},
+		{"worldbank.base_url", "WORLDBANK_BASE_URL", "World Bank API root", stringVar(&cfg.WorldBank.BaseURL)},
+		{"worldbank.headers", "WORLDBANK_HEADERS", "extra World Bank request headers", headersVar(&cfg.WorldBank.Headers)},
+		{"worldbank.propagate_trace", "WORLDBANK_PROPAGATE_TRACE", "send trace context headers to World Bank, only for internal services", boolVar(&cfg.WorldBank.PropagateTrace)},
+
+		{"alerts.rules_file", "ALERT_RULES_FILE", "JSON file with alert rules", stringVar(&cfg.Alerts.RulesFile)},
+		{"subscriptions.check_interval", "SUBSCRIPTION_CHECK_INTERVAL", "how often subscriptions are evaluated", durationVar(&cfg.Subscriptions.CheckInterval)},
+		{"worker.cities", "WORKER_CITIES", "cities refreshed in the background", listVar(&cfg.Worker.Cities)},
+		{"worker.interval", "WORKER_INTERVAL", "background refresh interval", durationVar(&cfg.Worker.Interval)},
+	}}
+}
+
+func (l *loader) problem(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+type flagValue struct {
+	b     binding
+	value string
+}
+
+// parseFlags parses args without applying them, so that flags can take
+// precedence over the file and environment they help locate.
+func (l *loader) parseFlags(args []string) ([]flagValue, string, error) {
+	fs := flag.NewFlagSet("towards", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML or TOML config file")
+
+	var values []flagValue
+	for _, b := range l.bindings {
+		b := b
+		fs.Func(b.key, fmt.Sprintf("%s (env %s)", b.usage, b.env), func(v string) error {
+			values = append(values, flagValue{b, v})
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, "", err
+	}
+	return values, *configFile, nil
+}
+
+func (l *loader) applyFlags(values []flagValue) {
+	for _, fv := range values {
+		if secretEnv[fv.b.env] {
+			secrets.Register(fv.value)
+		}
+		if err := fv.b.set(fv.value); err != nil {
+			l.problem("flag -%s: %v", fv.b.key, err)
+		}
+	}
+}
+
+func (l *loader) applyEnv() {
+	for _, b := range l.bindings {
+		if secretEnv[b.env] {
+			v, ok, err := secrets.Lookup(b.env)
+			if err != nil {
+				l.problem("secret %s: %v", b.env, err)
+				continue
+			}
+			if ok {
+				if err := b.set(v); err != nil {
+					l.problem("secret %s: %v", b.env, err)
+				}
+			}
+			continue
+		}
+		if v, ok := os.LookupEnv(b.env); ok {
+			if err := b.set(v); err != nil {
+				l.problem("env %s: %v", b.env, err)
+			}
+		}
+	}
+}
+
+func (l *loader) applyFile(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.problem("config file: %v", err)
+		return
+	}
+
+	var raw map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		l.problem("config file %s: extension must be .yaml, .yml or .toml", path)
+		return
+	}
+	if err != nil {
+		l.problem("config file %s: %v", path, err)
+		return
+	}
+
+	flat := map[string]interface{}{}
+	flatten("", raw, flat)
+
+	byKey := map[string]binding{}
+	for _, b := range l.bindings {
+		byKey[b.key] = b
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		b, ok := byKey[k]
+		if !ok {
+			l.problem("config file %s: unknown key %q", path, k)
+			continue
+		}
+		v := fileValueString(flat[k])
+		if secretEnv[b.env] {
+			secrets.Register(v)
+		}
+		if err := b.set(v); err != nil {
+			l.problem("config file %s: %s: %v", path, k, err)
+		}
+	}
+}
+
+// flatten turns nested sections into dotted keys. Maps below the second level
+// (rate_limit.routes) are kept as values.
+func flatten(prefix string, m map[string]interface{}, out map[string]interface{}) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]interface{}); ok && prefix == "" {
+			flatten(key, sub, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// fileValueString renders a decoded file value in the same syntax accepted
+// from the environment: lists become comma separated and maps "k=v" pairs.
+func fileValueString(v interface{}) string {
+	switch t := v.(type) {
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(t))
+		for _, k := range keys {
+			parts = append(parts, k+"="+fmt.Sprint(t[k]))
+		}
+		return strings.Join(parts, ",")
+	case time.Time:
+		// Unquoted YAML and TOML dates.
+		return t.Format(time.DateOnly)
+	}
+	return fmt.Sprint(v)
+}
+
+func stringVar(p *string) func(string) error {
+	return func(v string) error {
+		*p = v
+		return nil
+	}
+}
+
+func boolVar(p *bool) func(string) error {
+	return func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*p = b
+		return nil
+	}
+}
+
+func intVar(p *int) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*p = n
+		return nil
+	}
+}
+
+func durationVar(p *time.Duration) func(string) error {
+	return func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*p = d
+		return nil
+	}
+}
+
+// dateVar parses a YYYY-MM-DD date in UTC; an empty value clears it.
+func dateVar(p *time.Time) func(string) error {
+	return func(v string) error {
+		if v == "" {
+			*p = time.Time{}
+			return nil
+		}
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", v)
+		}
+		*p = t
+		return nil
+	}
+}
+
+func listVar(p *[]string) func(string) error {
+	return func(v string) error {
+		*p = splitList(v)
+		return nil
+	}
+}
+
+func limitVar(p *ratelimit.Limit) func(string) error {
+	return func(v string) error {
+		lim, err := ratelimit.ParseLimit(v)
+		if err != nil {
+			return err
+		}
+		*p = lim
+		return nil
+	}
+}
+
+func routeLimitsVar(p *map[string]ratelimit.Limit) func(string) error {
+	return func(v string) error {
+		routes := map[string]ratelimit.Limit{}
+		for _, entry := range splitList(v) {
+			route, spec, ok := strings.Cut(entry, "=")
+			if !ok {
+				return fmt.Errorf("entry %q: expected <route>=<limit>", entry)
+			}
+			lim, err := ratelimit.ParseLimit(spec)
+			if err != nil {
+				return err
+			}
+			routes[strings.TrimSpace(route)] = lim
+		}
+		*p = routes
+		return nil
+	}
+}
+
+// headersVar parses "Name=value" pairs separated by commas. Values are
+// registered for redaction since they usually hold credentials.
+func headersVar(p *map[string]string) func(string) error {
+	return func(v string) error {
+		headers := map[string]string{}
+		for _, entry := range splitList(v) {
+			name, value, ok := strings.Cut(entry, "=")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return fmt.Errorf("entry %q: expected <header>=<value>", entry)
+			}
+			value = strings.TrimSpace(value)
+			secrets.Register(value)
+			headers[name] = value
+		}
+		*p = headers
+		return nil
+	}
+}
+
+// splitList splits a comma separated value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ValidationError lists every problem found while loading the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (c *Config) validate(l *loader) {
+	if c.Database.URL == "" && !l.databaseOptional {
+		l.problem("database.url (DATABASE_URL) is required")
+	}
+	replay := c.Outbound.Mode == "replay"
+	if c.Weather.APIKey == "" && !replay {
+		l.problem("weather.api_key (VISUAL_CROSSING_KEY) is required")
+	}
+	if c.AI.APIKey == "" && !replay {
+		l.problem("ai.api_key (GEMINI_API_KEY) is required")
+	}
+	if c.AI.Model == "" {
+		l.problem("ai.model (GEMINI_MODEL) must not be empty")
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		l.problem("server.port (PORT) must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+
+	for _, p := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			l.problem("server.trusted_proxies (TRUSTED_PROXIES): %q is not an IP or CIDR", p)
+		}
+	}
+
+	if !c.API.LegacySunset.IsZero() && !c.API.LegacySunset.After(c.API.LegacyDeprecatedAt) {
+		l.problem("api.legacy_sunset (API_LEGACY_SUNSET) must be after api.legacy_deprecated_at")
+	}
+
+	if c.Server.GRPCPort != "" {
+		if port, err := strconv.Atoi(c.Server.GRPCPort); err != nil || port < 1 || port > 65535 {
+			l.problem("server.grpc_port (GRPC_PORT) must be a number between 1 and 65535, got %q", c.Server.GRPCPort)
+		} else if c.Server.GRPCPort == c.Server.Port {
+			l.problem("server.grpc_port (GRPC_PORT) must differ from server.port")
+		}
+	}
+
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		l.problem("log.format (LOG_FORMAT) must be text or json, got %q", c.Log.Format)
+	}
+	if _, err := log.ParseLevel(c.Log.Level); err != nil {
+		l.problem("log.level (LOG_LEVEL): %v", err)
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		l.problem("tracing.exporter (OTEL_TRACES_EXPORTER) must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+
+	switch c.Outbound.Mode {
+	case "live", "record", "replay":
+		if c.Outbound.Mode != "live" && c.Outbound.FixturesDir == "" {
+			l.problem("outbound.fixtures_dir (UPSTREAM_FIXTURES_DIR) is required in %s mode", c.Outbound.Mode)
+		}
+	default:
+		l.problem("outbound.mode (UPSTREAM_MODE) must be live, record or replay, got %q", c.Outbound.Mode)
+	}
+
+	if c.RateLimit.Backend != "memory" && c.RateLimit.Backend != "postgres" {
+		l.problem("rate_limit.backend (RATE_LIMIT_BACKEND) must be memory or postgres, got %q", c.RateLimit.Backend)
+	}
+
+	positive := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"server.read_timeout (SERVER_READ_TIMEOUT)", c.Server.ReadTimeout},
+		{"server.write_timeout (SERVER_WRITE_TIMEOUT)", c.Server.WriteTimeout},
+		{"server.idle_timeout (SERVER_IDLE_TIMEOUT)", c.Server.IdleTimeout},
+		{"server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT)", c.Server.ShutdownTimeout},
+		{"server.health_cache_ttl (HEALTH_CACHE_TTL)", c.Server.HealthCacheTTL},
+		{"outbound.timeout (OUTBOUND_TIMEOUT)", c.Outbound.Timeout},
+		{"outbound.retry_max_delay (OUTBOUND_RETRY_MAX_DELAY)", c.Outbound.RetryMaxDelay},
+		{"weather.timeout (WEATHER_TIMEOUT)", c.Weather.Timeout},
+		{"ai.timeout (AI_TIMEOUT)", c.AI.Timeout},
+		{"geocoding.timeout (GEOCODING_TIMEOUT)", c.Geocoding.Timeout},
+		{"usgs.timeout (USGS_TIMEOUT)", c.USGS.Timeout},
+		{"countries.timeout (COUNTRIES_TIMEOUT)", c.Countries.Timeout},
+		{"worldbank.timeout (WORLDBANK_TIMEOUT)", c.WorldBank.Timeout},
+		{"subscriptions.check_interval (SUBSCRIPTION_CHECK_INTERVAL)", c.Subscriptions.CheckInterval},
+		{"worker.interval (WORKER_INTERVAL)", c.Worker.Interval},
+	}
+	for _, p := range positive {
+		if p.d <= 0 {
+			l.problem("%s must be a positive duration", p.name)
+		}
+	}
+	baseURLs := []struct {
+		name string
+		url  string
+	}{
+		{"weather.base_url (WEATHER_BASE_URL)", c.Weather.BaseURL},
+		{"ai.base_url (AI_BASE_URL)", c.AI.BaseURL},
+		{"geocoding.base_url (GEOCODING_BASE_URL)", c.Geocoding.BaseURL},
+		{"usgs.base_url (USGS_BASE_URL)", c.USGS.BaseURL},
+		{"countries.base_url (COUNTRIES_BASE_URL)", c.Countries.BaseURL},
+		{"worldbank.base_url (WORLDBANK_BASE_URL)", c.WorldBank.BaseURL},
+	}
+	for _, b := range baseURLs {
+		u, err := url.Parse(b.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			l.problem("%s must be an absolute http(s) URL, got %q", b.name, b.url)
+		}
+	}
+
+	if c.Outbound.Retries < 0 {
+		l.problem("outbound.retries (OUTBOUND_RETRIES) must not be negative")
+	}
+	if c.Outbound.RetryBaseDelay < 0 {
+		l.problem("outbound.retry_base_delay (OUTBOUND_RETRY_BASE_DELAY) must not be negative")
+	}
+	if c.Outbound.BreakerThreshold < 0 {
+		l.problem("outbound.breaker_threshold (OUTBOUND_BREAKER_THRESHOLD) must not be negative")
+	}
+	if c.Outbound.BreakerThreshold > 0 && c.Outbound.BreakerCooldown <= 0 {
+		l.problem("outbound.breaker_cooldown (OUTBOUND_BREAKER_COOLDOWN) must be a positive duration")
+	}
+	if c.Geocoding.MinInterval < 0 {
+		l.problem("geocoding.min_interval (GEOCODING_MIN_INTERVAL) must not be negative")
+	}
+	if c.CORS.MaxAge < 0 {
+		l.problem("cors.max_age (CORS_MAX_AGE) must not be negative")
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		l.problem("cors.allowed_methods (CORS_ALLOWED_METHODS) must not be empty")
+	}
+	if c.CORS.AllowCredentials {
+		for _, o := range c.CORS.AllowedOrigins {
+			if o == "*" {
+				// The request origin is echoed back, so any site could make
+				// credentialed reads.
+				l.problem("cors.allow_credentials (CORS_ALLOW_CREDENTIALS) requires explicit cors.allowed_origins, not \"*\"")
+			}
+		}
+	}
+	if c.Geocoding.UserAgent == "" {
+		l.problem("geocoding.user_agent (GEOCODING_USER_AGENT) is required by the Nominatim usage policy")
+	}
+}
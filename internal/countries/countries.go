@@ -0,0 +1,259 @@
+package countries
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Indicator is a World Bank development indicator.
+type Indicator struct {
+	ID   string
+	Unit string
+}
+
+var (
+	GDP             = Indicator{"NY.GDP.MKTP.CD", "current US$"}
+	GDPPerCapita    = Indicator{"NY.GDP.PCAP.CD", "current US$"}
+	LifeExpectancy  = Indicator{"SP.DYN.LE00.IN", "years"}
+	Unemployment    = Indicator{"SL.UEM.TOTL.ZS", "% of labor force"}
+	UrbanPopulation = Indicator{"SP.URB.TOTL.IN.ZS", "% of population"}
+	// CO2PerCapita excludes land use change; it replaced the archived
+	// EN.ATM.CO2E.PC series in 2024.
+	CO2PerCapita = Indicator{"EN.GHG.CO2.PC.CE.AR5", "t CO2e per capita"}
+)
+
+// Lookback is how many years before the current one are searched for a
+// non-null value. Most indicators lag one to three years, some longer.
+const Lookback = 10
+
+// ErrNotFound is returned for codes the World Bank does not know, and for
+// aggregates such as WLD or EUU, which share the country endpoints.
+var ErrNotFound = errors.New("country not found")
+
+// StatusError reports a non-200 World Bank response.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "world bank: unexpected status " + e.Status
+}
+
+// Value is the latest non-null observation of an indicator.
+type Value struct {
+	Indicator string  `json:"indicator"`
+	Value     float64 `json:"value"`
+	Year      int     `json:"year"`
+	Unit      string  `json:"unit"`
+}
+
+// Profile is a country's World Bank metadata and latest indicators.
+// Indicators without a value in the lookback window are omitted.
+type Profile struct {
+	Code        string `json:"code"` // ISO 3166-1 alpha-3
+	ISO2        string `json:"iso2"`
+	Name        string `json:"name"`
+	Region      string `json:"region,omitempty"`
+	IncomeLevel string `json:"income_level,omitempty"`
+	Capital     string `json:"capital,omitempty"`
+
+	GDPPerCapita    *Value `json:"gdp_per_capita,omitempty"`
+	LifeExpectancy  *Value `json:"life_expectancy,omitempty"`
+	Unemployment    *Value `json:"unemployment,omitempty"`
+	CO2PerCapita    *Value `json:"co2_per_capita,omitempty"`
+	UrbanPopulation *Value `json:"urban_population,omitempty"`
+}
+
+// ValidCode reports whether code looks like an ISO 3166-1 alpha-2 or
+// alpha-3 code.
+func ValidCode(code string) bool {
+	if len(code) != 2 && len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// Client reads the World Bank v2 API.
+type Client struct {
+	base string
+	http *http.Client
+	now  func() time.Time
+}
+
+// NewClient returns a client for the API rooted at baseURL, using
+// httpClient, which is expected to apply the upstream's timeout and retries.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	return &Client{base: strings.TrimRight(baseURL, "/") + "/", http: httpClient, now: time.Now}
+}
+
+// Profile fetches the metadata and the profile indicators for code, an
+// alpha-2 or alpha-3 code, in two requests.
+func (c *Client) Profile(ctx context.Context, code string) (*Profile, error) {
+	var meta []struct {
+		ID          string `json:"id"`
+		ISO2        string `json:"iso2Code"`
+		Name        string `json:"name"`
+		Capital     string `json:"capitalCity"`
+		Region      struct{ ID, Value string } `json:"region"`
+		IncomeLevel struct{ Value string } `json:"incomeLevel"`
+	}
+	if err := c.get(ctx, "country/"+url.PathEscape(code)+"?format=json", &meta); err != nil {
+		return nil, err
+	}
+	if len(meta) == 0 {
+		return nil, ErrNotFound
+	}
+	m := meta[0]
+	if m.Region.ID == "NA" || m.Region.Value == "Aggregates" {
+		return nil, ErrNotFound
+	}
+	p := &Profile{
+		Code:        m.ID,
+		ISO2:        m.ISO2,
+		Name:        m.Name,
+		Region:      strings.TrimSpace(m.Region.Value),
+		IncomeLevel: strings.TrimSpace(m.IncomeLevel.Value),
+		Capital:     m.Capital,
+	}
+
+	fields := []struct {
+		ind Indicator
+		dst **Value
+	}{
+		{GDPPerCapita, &p.GDPPerCapita},
+		{LifeExpectancy, &p.LifeExpectancy},
+		{Unemployment, &p.Unemployment},
+		{CO2PerCapita, &p.CO2PerCapita},
+		{UrbanPopulation, &p.UrbanPopulation},
+	}
+	inds := make([]Indicator, 0, len(fields))
+	for _, f := range fields {
+		inds = append(inds, f.ind)
+	}
+	latest, err := c.latest(ctx, p.Code, inds...)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		*f.dst = latest[f.ind.ID]
+	}
+	return p, nil
+}
+
+// Latest returns the most recent non-null value of ind for code, or nil when
+// every year in the lookback window is null.
+func (c *Client) Latest(ctx context.Context, code string, ind Indicator) (*Value, error) {
+	latest, err := c.latest(ctx, code, ind)
+	if err != nil {
+		return nil, err
+	}
+	return latest[ind.ID], nil
+}
+
+// latest fetches every year of the lookback window for inds in one request
+// and walks back from the newest year to the first non-null value of each.
+func (c *Client) latest(ctx context.Context, code string, inds ...Indicator) (map[string]*Value, error) {
+	ids := make([]string, 0, len(inds))
+	units := make(map[string]string, len(inds))
+	for _, ind := range inds {
+		ids = append(ids, ind.ID)
+		units[ind.ID] = ind.Unit
+	}
+	year := c.now().Year()
+	path := fmt.Sprintf("country/%s/indicator/%s?format=json&date=%d:%d&per_page=%d",
+		url.PathEscape(code), strings.Join(ids, ";"), year-Lookback, year, len(inds)*(Lookback+1))
+	if len(inds) > 1 {
+		// Several indicators in one request must name their source, here
+		// World Development Indicators.
+		path += "&source=2"
+	}
+
+	var rows []struct {
+		Indicator struct {
+			ID string `json:"id"`
+		} `json:"indicator"`
+		Date  string   `json:"date"`
+		Value *float64 `json:"value"`
+	}
+	if err := c.get(ctx, path, &rows); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*Value, len(inds))
+	for _, r := range rows {
+		unit, ok := units[r.Indicator.ID]
+		if !ok || r.Value == nil {
+			continue
+		}
+		y, err := strconv.Atoi(r.Date)
+		if err != nil {
+			continue
+		}
+		if cur := out[r.Indicator.ID]; cur == nil || y > cur.Year {
+			out[r.Indicator.ID] = &Value{Indicator: r.Indicator.ID, Value: *r.Value, Year: y, Unit: unit}
+		}
+	}
+	return out, nil
+}
+
+// get decodes the data element of a World Bank response into out. The API
+// answers [pagination, data] on success and [{"message": [...]}] for errors,
+// both with status 200.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	var body []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("world bank: decode response: %w", err)
+	}
+	if len(body) == 0 {
+		return fmt.Errorf("world bank: empty response")
+	}
+	var head struct {
+		Message []struct {
+			ID    string `json:"id"`
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(body[0], &head); err == nil && len(head.Message) > 0 {
+		msg := head.Message[0]
+		// 120 is "Invalid value", the answer for unknown country codes.
+		if msg.ID == "120" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("world bank: %s: %s", msg.Key, msg.Value)
+	}
+	if len(body) < 2 || string(body[1]) == "null" {
+		// No rows, e.g. no data at all for the requested years.
+		return nil
+	}
+	if err := json.Unmarshal(body[1], out); err != nil {
+		return fmt.Errorf("world bank: decode data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package countries_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/countries"
+)
+
+// worldBank serves the country endpoint from meta and the indicator endpoint
+// from data, keyed by the requested code, and keeps every request URL.
+type worldBank struct {
+	*httptest.Server
+	mu   sync.Mutex
+	urls []string
+}
+
+func newWorldBank(t *testing.T, meta map[string]string, data string) *worldBank {
+	wb := &worldBank{}
+	wb.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wb.mu.Lock()
+		wb.urls = append(wb.urls, r.URL.String())
+		wb.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/country/"), "/")
+		if len(parts) == 1 {
+			body, ok := meta[parts[0]]
+			if !ok {
+				body = invalidValue
+			}
+			fmt.Fprint(w, body)
+			return
+		}
+		fmt.Fprint(w, data)
+	}))
+	t.Cleanup(wb.Close)
+	return wb
+}
+
+func (wb *worldBank) requests() []string {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return append([]string(nil), wb.urls...)
+}
+
+const invalidValue = `[{"message":[{"id":"120","key":"Invalid value","value":"The provided parameter value is not valid"}]}]`
+
+const franceMeta = `[{"page":1,"pages":1,"per_page":"50","total":1},[{"id":"FRA","iso2Code":"FR","name":"France",
+	"region":{"id":"ECS","iso2code":"Z7","value":"Europe & Central Asia"},
+	"incomeLevel":{"id":"HIC","iso2code":"XD","value":"High income"},"capitalCity":"Paris"}]]`
+
+func aggregateMeta(id, name string) string {
+	return fmt.Sprintf(`[{"page":1,"pages":1,"per_page":"50","total":1},[{"id":%q,"iso2Code":"1W","name":%q,
+	"region":{"id":"NA","iso2code":"NA","value":"Aggregates"},
+	"incomeLevel":{"id":"NA","iso2code":"NA","value":"Aggregates"},"capitalCity":""}]]`, id, name)
+}
+
+// rows builds an indicator response; values maps years before the current
+// one to a value, or nil for a null observation.
+func rows(id string, values map[int]*float64) string {
+	year := time.Now().Year()
+	var out []string
+	for back := 0; back <= countries.Lookback; back++ {
+		v, ok := values[back]
+		value := "null"
+		if ok && v != nil {
+			value = fmt.Sprint(*v)
+		}
+		out = append(out, fmt.Sprintf(`{"indicator":{"id":%q},"date":"%d","value":%s}`, id, year-back, value))
+	}
+	return strings.Join(out, ",")
+}
+
+func f(v float64) *float64 { return &v }
+
+func page(data ...string) string {
+	return `[{"page":1,"pages":1},[` + strings.Join(data, ",") + `]]`
+}
+
+func TestLatestWalksBack(t *testing.T) {
+	year := time.Now().Year()
+	tests := []struct {
+		name     string
+		values   map[int]*float64
+		want     *countries.Value
+		wantNone bool
+	}{
+		{
+			name:   "current year",
+			values: map[int]*float64{0: f(3.1), 1: f(3.0)},
+			want:   &countries.Value{Value: 3.1, Year: year},
+		},
+		{
+			name:   "newest years null",
+			values: map[int]*float64{0: nil, 1: nil, 2: f(2.9), 3: f(2.8)},
+			want:   &countries.Value{Value: 2.9, Year: year - 2},
+		},
+		{
+			name:   "oldest year in window",
+			values: map[int]*float64{countries.Lookback: f(1.5)},
+			want:   &countries.Value{Value: 1.5, Year: year - countries.Lookback},
+		},
+		{name: "all null", values: map[int]*float64{}, wantNone: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wb := newWorldBank(t, nil, page(rows(countries.GDP.ID, tt.values)))
+			c := countries.NewClient(wb.URL+"/v2/", wb.Client())
+
+			got, err := c.Latest(context.Background(), "FRA", countries.GDP)
+			if err != nil {
+				t.Fatalf("Latest: %v", err)
+			}
+			if tt.wantNone {
+				if got != nil {
+					t.Errorf("got %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Value != tt.want.Value || got.Year != tt.want.Year ||
+				got.Indicator != countries.GDP.ID || got.Unit != countries.GDP.Unit {
+				t.Errorf("got %+v, want %v in %d", got, tt.want.Value, tt.want.Year)
+			}
+
+			reqs := wb.requests()
+			wantDate := fmt.Sprintf("date=%d:%d", year-countries.Lookback, year)
+			if len(reqs) != 1 || !strings.Contains(reqs[0], wantDate) {
+				t.Errorf("requests = %v, want one with %s", reqs, wantDate)
+			}
+		})
+	}
+}
+
+func TestLatestNoData(t *testing.T) {
+	// The API answers [pagination, null] when no year has any row.
+	wb := newWorldBank(t, nil, `[{"page":0,"pages":0,"total":0},null]`)
+	c := countries.NewClient(wb.URL+"/v2/", wb.Client())
+	got, err := c.Latest(context.Background(), "FRA", countries.GDP)
+	if err != nil || got != nil {
+		t.Errorf("got %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	data := page(
+		rows(countries.GDPPerCapita.ID, map[int]*float64{0: nil, 1: f(44460.8)}),
+		rows(countries.LifeExpectancy.ID, map[int]*float64{2: f(82.3)}),
+		rows(countries.Unemployment.ID, map[int]*float64{1: f(7.3)}),
+		rows(countries.UrbanPopulation.ID, map[int]*float64{1: f(81.8)}),
+		// Rows for indicators that were not asked for are ignored.
+		rows("SP.POP.TOTL", map[int]*float64{0: f(68e6)}),
+	)
+	wb := newWorldBank(t, map[string]string{"FR": franceMeta}, data)
+	c := countries.NewClient(wb.URL+"/v2/", wb.Client())
+
+	p, err := c.Profile(context.Background(), "FR")
+	if err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+	year := time.Now().Year()
+	if p.Code != "FRA" || p.ISO2 != "FR" || p.Name != "France" || p.Capital != "Paris" ||
+		p.Region != "Europe & Central Asia" || p.IncomeLevel != "High income" {
+		t.Errorf("metadata = %+v", p)
+	}
+	if p.GDPPerCapita == nil || p.GDPPerCapita.Value != 44460.8 || p.GDPPerCapita.Year != year-1 {
+		t.Errorf("GDPPerCapita = %+v", p.GDPPerCapita)
+	}
+	if p.LifeExpectancy == nil || p.LifeExpectancy.Year != year-2 {
+		t.Errorf("LifeExpectancy = %+v", p.LifeExpectancy)
+	}
+	if p.CO2PerCapita != nil {
+		t.Errorf("CO2PerCapita = %+v, want nil", p.CO2PerCapita)
+	}
+
+	reqs := wb.requests()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %v, want 2", reqs)
+	}
+	// The indicators are fetched for the alpha-3 code in one request.
+	if !strings.HasPrefix(reqs[1], "/v2/country/FRA/indicator/") || !strings.Contains(reqs[1], "source=2") {
+		t.Errorf("indicator request = %s", reqs[1])
+	}
+}
+
+func TestProfileNotFound(t *testing.T) {
+	meta := map[string]string{
+		"WLD": aggregateMeta("WLD", "World"),
+		"EUU": aggregateMeta("EUU", "European Union"),
+		"HIC": aggregateMeta("HIC", "High income"),
+		"1W":  aggregateMeta("WLD", "World"),
+		"XX":  `[{"page":1,"pages":0,"total":0},[]]`,
+	}
+	for _, code := range []string{"WLD", "EUU", "HIC", "1W", "XX", "ZZZ"} {
+		t.Run(code, func(t *testing.T) {
+			wb := newWorldBank(t, meta, page())
+			c := countries.NewClient(wb.URL+"/v2/", wb.Client())
+
+			p, err := c.Profile(context.Background(), code)
+			if !errors.Is(err, countries.ErrNotFound) {
+				t.Fatalf("got %+v, %v; want ErrNotFound", p, err)
+			}
+			if reqs := wb.requests(); len(reqs) != 1 {
+				t.Errorf("requests = %v, want only the metadata", reqs)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		notFound bool
+		want     string
+	}{
+		{name: "invalid value", status: http.StatusOK, body: invalidValue, notFound: true},
+		{
+			name:   "other message",
+			status: http.StatusOK,
+			body:   `[{"message":[{"id":"175","key":"Invalid format","value":"The indicator was not found."}]}]`,
+			want:   "world bank: Invalid format: The indicator was not found.",
+		},
+		{name: "empty", status: http.StatusOK, body: `[]`, want: "world bank: empty response"},
+		{name: "status", status: http.StatusBadGateway, body: `bad gateway`, want: "world bank: unexpected status 502 Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+			c := countries.NewClient(srv.URL, srv.Client())
+
+			_, err := c.Latest(context.Background(), "FRA", countries.GDP)
+			if tt.notFound {
+				if !errors.Is(err, countries.ErrNotFound) {
+					t.Errorf("err = %v, want ErrNotFound", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want || errors.Is(err, countries.ErrNotFound) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+			var se *countries.StatusError
+			if tt.status != http.StatusOK && (!errors.As(err, &se) || se.StatusCode != tt.status) {
+				t.Errorf("err = %#v, want StatusError %d", err, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id                 BIGSERIAL PRIMARY KEY,
+    city               TEXT NOT NULL,
+    webhook_url        TEXT NOT NULL,
+    secret             TEXT NOT NULL,
+    min_comfort_index  DOUBLE PRECISION,
+    min_severity       TEXT NOT NULL DEFAULT 'severe',
+    active             BOOLEAN NOT NULL DEFAULT TRUE,
+    last_comfort_index DOUBLE PRECISION,
+    last_alert_ids     TEXT[] NOT NULL DEFAULT '{}',
+    last_checked_at    TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_active_city_idx ON subscriptions (city) WHERE active;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    event           TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    status_code     INTEGER,
+    attempts        INTEGER NOT NULL,
+    error           TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at DESC);
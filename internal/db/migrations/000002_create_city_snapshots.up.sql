@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS city_snapshots (
+    id                        BIGSERIAL PRIMARY KEY,
+    city                      TEXT NOT NULL,
+    temperature               TEXT NOT NULL,
+    quality_index             TEXT NOT NULL,
+    workload_points           TEXT NOT NULL,
+    safety_assessment         TEXT NOT NULL,
+    general_rating_of_comfort TEXT NOT NULL,
+    payload                   JSONB NOT NULL,
+    collected_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS city_snapshots_city_collected_idx ON city_snapshots (city, collected_at DESC);
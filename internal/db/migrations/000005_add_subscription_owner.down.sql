@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS subscriptions_api_key_idx;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS api_key_id;
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS api_key_id BIGINT REFERENCES api_keys (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS subscriptions_api_key_idx ON subscriptions (api_key_id);
@@ -0,0 +1,263 @@
+package fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/publicthrone547/towards_project/internal/logging"
+)
+
+// Upstream modes.
+const (
+	Live   = "live"
+	Record = "record"
+	Replay = "replay"
+)
+
+// Fixture is one recorded upstream exchange as stored on disk.
+type Fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body"`
+}
+
+// MissingError is returned in replay mode when no fixture matches a request.
+type MissingError struct {
+	Method string
+	URL    string
+	Path   string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("no fixture for %s %s (expected %s)", e.Method, e.URL, e.Path)
+}
+
+// Store maps requests to fixture files under a directory, one subdirectory per
+// host. Query parameters listed as ignored are dropped before matching and
+// never written, which keeps API keys out of fixtures and lets parameters
+// derived from the clock (such as a search window ending today) match.
+type Store struct {
+	dir    string
+	ignore map[string]bool
+	// Fallback makes replay answer a request without an exact fixture with
+	// the first one, by name, recorded for the same method and path. Off, a
+	// changed query or prompt fails with MissingError.
+	Fallback bool
+}
+
+// NewStore returns a store rooted at dir.
+func NewStore(dir string, ignoreParams ...string) *Store {
+	ignore := make(map[string]bool, len(ignoreParams))
+	for _, p := range ignoreParams {
+		ignore[p] = true
+	}
+	return &Store{dir: dir, ignore: ignore}
+}
+
+// Transport returns the base RoundTripper for mode: next itself when live,
+// next with every response saved when recording, or the fixtures alone when
+// replaying.
+func (s *Store) Transport(mode string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	switch mode {
+	case Record:
+		return &recorder{store: s, next: next}
+	case Replay:
+		return &player{store: s}
+	}
+	return next
+}
+
+// hashLen is how many bytes of the request hash go into a fixture name.
+const hashLen = 6
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// normalize returns the URL without ignored query parameters and with the
+// rest sorted.
+func (s *Store) normalize(u *url.URL) string {
+	n := *u
+	q := n.Query()
+	for p := range q {
+		if s.ignore[p] {
+			q.Del(p)
+		}
+	}
+	n.RawQuery = q.Encode()
+	n.Fragment = ""
+	return n.String()
+}
+
+// stem is the file name shared by every fixture for the method and path;
+// name adds a hash of the normalized URL and body to tell them apart.
+func (s *Store) stem(method string, u *url.URL) string {
+	slug := strings.Trim(unsafeChars.ReplaceAllString(u.EscapedPath(), "_"), "_")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	return filepath.Join(s.dir, unsafeChars.ReplaceAllString(u.Host, "_"), strings.ToLower(method)+"-"+slug)
+}
+
+func (s *Store) name(method string, u *url.URL, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, s.normalize(u))
+	h.Write(body)
+	return s.stem(method, u) + "-" + hex.EncodeToString(h.Sum(nil)[:hashLen]) + ".json"
+}
+
+// readBody returns the request body and leaves req able to send it again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+type recorder struct {
+	store *Store
+	next  http.RoundTripper
+}
+
+// RoundTrip forwards the request and saves the response, whatever its
+// status, so error paths can be replayed too. The body is read in full before
+// it is returned, so streamed responses arrive at once while recording.
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	f := Fixture{
+		Method:      req.Method,
+		URL:         r.store.normalize(req.URL),
+		RequestBody: string(body),
+		Status:      resp.StatusCode,
+		Body:        string(data),
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		f.Header = http.Header{"Content-Type": {ct}}
+	}
+	path := r.store.name(req.Method, req.URL, body)
+	if err := write(path, &f); err != nil {
+		logging.FromContext(req.Context()).Warnf("record fixture %s: %v", path, err)
+	}
+	return resp, nil
+}
+
+// write saves f atomically so concurrent recordings of the same request
+// never leave a truncated file.
+func write(path string, f *Fixture) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type player struct {
+	store *Store
+}
+
+// RoundTrip serves the fixture recorded for the same method, URL and body, or
+// with Fallback set, the first one recorded for the same method and path.
+func (p *player) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The request ends here, so its body is consumed rather than restored.
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	path := p.store.name(req.Method, req.URL, body)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		missing := &MissingError{Method: req.Method, URL: p.store.normalize(req.URL), Path: path}
+		if !p.store.Fallback {
+			return nil, missing
+		}
+		matches, _ := filepath.Glob(p.store.stem(req.Method, req.URL) + "-" + strings.Repeat("?", 2*hashLen) + ".json")
+		if len(matches) == 0 {
+			return nil, missing
+		}
+		sort.Strings(matches)
+		logging.FromContext(req.Context()).Warnf("no exact fixture for %s %s, falling back to %s", req.Method, req.URL.Path, matches[0])
+		path = matches[0]
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+	header := f.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
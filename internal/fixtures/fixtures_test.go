@@ -0,0 +1,198 @@
+package fixtures_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/publicthrone547/towards_project/internal/fixtures"
+)
+
+// upstream answers with the request it got, so replayed bodies show which
+// recording they came from.
+func upstream(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/missing" {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "not recorded")
+		fmt.Fprintf(w, `{"q":%q,"body":%q}`, r.URL.Query().Get("q"), body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// noNetwork fails every request, to prove replay never reaches next.
+type noNetwork struct{}
+
+func (noNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("network request to %s", req.URL)
+}
+
+func send(t *testing.T, rt http.RoundTripper, method, url, body string) (*http.Response, string, error) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data), nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	var calls atomic.Int32
+	srv := upstream(t, &calls)
+	dir := t.TempDir()
+
+	rec := fixtures.NewStore(dir, "key", "endtime").Transport(fixtures.Record, http.DefaultTransport)
+	recorded := []struct {
+		method, url, body string
+	}{
+		{http.MethodGet, srv.URL + "/search?q=paris&key=s3cret-key&endtime=2026-01-01", ""},
+		{http.MethodPost, srv.URL + "/generate?key=s3cret-key", `{"prompt":"hello"}`},
+		{http.MethodGet, srv.URL + "/missing?key=s3cret-key", ""},
+	}
+	for _, r := range recorded {
+		if _, _, err := send(t, rec, r.method, r.url, r.body); err != nil {
+			t.Fatalf("record %s %s: %v", r.method, r.url, err)
+		}
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("upstream calls while recording = %d, want 3", n)
+	}
+
+	// Ignored parameters are never written, in names or contents.
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, leak := range []string{"s3cret-key", "endtime", "2026-01-01"} {
+			if strings.Contains(path, leak) || strings.Contains(string(data), leak) {
+				t.Errorf("%s contains %q", path, leak)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	play := fixtures.NewStore(dir, "key", "endtime").Transport(fixtures.Replay, noNetwork{})
+	tests := []struct {
+		name, method, url, body string
+		status                  int
+		want                    string
+	}{
+		{
+			name:   "ignored params differ",
+			method: http.MethodGet, url: srv.URL + "/search?key=other&endtime=2030-12-31&q=paris",
+			status: http.StatusOK, want: `{"q":"paris","body":""}`,
+		},
+		{
+			name:   "same body",
+			method: http.MethodPost, url: srv.URL + "/generate?key=other", body: `{"prompt":"hello"}`,
+			status: http.StatusOK, want: `{"q":"","body":"{\"prompt\":\"hello\"}"}`,
+		},
+		{
+			name:   "error status",
+			method: http.MethodGet, url: srv.URL + "/missing",
+			status: http.StatusNotFound, want: "{\"error\":\"not found\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body, err := send(t, play, tt.method, tt.url, tt.body)
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if resp.StatusCode != tt.status || body != tt.want {
+				t.Errorf("got %d %q, want %d %q", resp.StatusCode, body, tt.status, tt.want)
+			}
+			if resp.Header.Get("X-Upstream") != "" {
+				t.Errorf("replayed header %q, want only Content-Type", resp.Header.Get("X-Upstream"))
+			}
+		})
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("upstream calls after replay = %d, want 3", n)
+	}
+}
+
+func TestReplayMissing(t *testing.T) {
+	var calls atomic.Int32
+	srv := upstream(t, &calls)
+	dir := t.TempDir()
+
+	rec := fixtures.NewStore(dir, "key").Transport(fixtures.Record, http.DefaultTransport)
+	if _, _, err := send(t, rec, http.MethodPost, srv.URL+"/generate", `{"prompt":"hello"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, method, url, body string
+	}{
+		{"different body", http.MethodPost, srv.URL + "/generate", `{"prompt":"goodbye"}`},
+		{"different query", http.MethodPost, srv.URL + "/generate?q=other", `{"prompt":"hello"}`},
+		{"different method", http.MethodPut, srv.URL + "/generate", `{"prompt":"hello"}`},
+		{"unknown path", http.MethodPost, srv.URL + "/other", `{"prompt":"hello"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			play := fixtures.NewStore(dir, "key").Transport(fixtures.Replay, noNetwork{})
+			_, _, err := send(t, play, tt.method, tt.url, tt.body)
+			var missing *fixtures.MissingError
+			if !errors.As(err, &missing) {
+				t.Fatalf("err = %v, want MissingError", err)
+			}
+			if missing.Method != tt.method || !strings.HasPrefix(missing.Path, dir) {
+				t.Errorf("MissingError = %+v", missing)
+			}
+		})
+	}
+
+	// With Fallback, a changed body or query on a recorded path is served the
+	// recording; other paths and methods still fail.
+	store := fixtures.NewStore(dir, "key")
+	store.Fallback = true
+	play := store.Transport(fixtures.Replay, noNetwork{})
+	for _, tt := range tests {
+		_, body, err := send(t, play, tt.method, tt.url, tt.body)
+		fallback := tt.name == "different body" || tt.name == "different query"
+		var missing *fixtures.MissingError
+		switch {
+		case fallback && err != nil:
+			t.Errorf("%s with fallback: %v", tt.name, err)
+		case fallback && !strings.Contains(body, "hello"):
+			t.Errorf("%s with fallback: body %q", tt.name, body)
+		case !fallback && !errors.As(err, &missing):
+			t.Errorf("%s with fallback: err = %v, want MissingError", tt.name, err)
+		}
+	}
+}
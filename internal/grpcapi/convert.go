@@ -0,0 +1,79 @@
+package grpcapi
+
+import (
+	towardsv1 "github.com/publicthrone547/towards_project/api/towards/v1"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// weatherToProto converts the v2 REST shape, which the message mirrors.
+func weatherToProto(w *handlers.WeatherResponseV2) *towardsv1.Weather {
+	out := &towardsv1.Weather{
+		City:       w.City,
+		Date:       w.Date,
+		Conditions: w.Conditions,
+		Weather: &towardsv1.WeatherMetrics{
+			Temperature: w.Weather.Temperature,
+			TempMin:     w.Weather.TempMin,
+			TempMax:     w.Weather.TempMax,
+			Humidity:    w.Weather.Humidity,
+			WindSpeed:   w.Weather.WindSpeed,
+			Pressure:    w.Weather.Pressure,
+		},
+		Thermal: &towardsv1.ThermalMetrics{
+			FeelsLike: w.Thermal.FeelsLike,
+			HeatIndex: w.Thermal.HeatIndex,
+			WindChill: w.Thermal.WindChill,
+			Humidex:   w.Thermal.Humidex,
+			Utci:      w.Thermal.UTCI,
+		},
+		Comfort: &towardsv1.ComfortMetrics{
+			Index:       w.Comfort.Index,
+			AirPurity:   int32(w.Comfort.AirPurity),
+			RoadTraffic: int32(w.Comfort.RoadTraffic),
+			CrimeRisks:  int32(w.Comfort.CrimeRisks),
+			BestHours:   w.Comfort.BestHours,
+		},
+	}
+	for _, h := range w.Hours {
+		out.Hours = append(out.Hours, &towardsv1.Hour{
+			Time:         h.Time,
+			Temperature:  h.Temperature,
+			FeelsLike:    h.FeelsLike,
+			Humidity:     h.Humidity,
+			PrecipProb:   h.PrecipProb,
+			WindSpeed:    h.WindSpeed,
+			UvIndex:      h.UVIndex,
+			Conditions:   h.Conditions,
+			ComfortScore: h.ComfortScore,
+		})
+	}
+	if c := w.Country; c != nil {
+		out.Country = &towardsv1.CountryMetrics{GdpUsd: c.GDPUSD, Population: c.Population, PopulationDensity: c.PopulationDensity}
+	}
+	if c := w.CityStats; c != nil {
+		out.CityStats = &towardsv1.CityMetrics{Population: c.Population, DensityPerKm2: c.DensityPerKm2}
+	}
+	if q := w.Earthquakes; q != nil {
+		eq := &towardsv1.EarthquakeMetrics{Risk: q.Risk, Count: int32(q.Count), MaxMag: q.MaxMag, MaxMagLastWeek: q.MaxMag7d}
+		for _, r := range q.Recent {
+			eq.Recent = append(eq.Recent, &towardsv1.Earthquake{Time: timestamppb.New(r.Time), Magnitude: r.Magnitude, Place: r.Place})
+		}
+		out.Earthquakes = eq
+	}
+	for _, a := range w.Alerts {
+		out.Alerts = append(out.Alerts, &towardsv1.Alert{
+			Id:        a.ID,
+			Severity:  string(a.Severity),
+			Title:     a.Title,
+			Guidance:  a.Guidance,
+			Metric:    a.Metric,
+			Value:     a.Value,
+			Threshold: a.Threshold,
+		})
+	}
+	if f := w.Forecast; f != nil {
+		out.Forecast = &towardsv1.Forecast{Text: f.Text, ErrorCode: string(f.ErrorCode)}
+	}
+	return out
+}
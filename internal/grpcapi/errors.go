@@ -0,0 +1,68 @@
+package grpcapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/secrets"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the google.rpc.ErrorInfo domain of API errors.
+const errorDomain = "towards"
+
+// httpCodes maps the HTTP status of an API error to the closest gRPC code.
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusUnprocessableEntity: codes.FailedPrecondition,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusBadGateway:          codes.Unavailable,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+func missing(detail string) *apierror.Error {
+	return apierror.New(apierror.CodeMissingParameter, "%s", detail)
+}
+
+// errorMessage is the client-facing message: the redacted detail, as in
+// problem documents.
+func errorMessage(e *apierror.Error) string {
+	if e.Detail != "" {
+		return secrets.Redact(e.Detail)
+	}
+	return e.Code.Title()
+}
+
+// statusFor converts err to a gRPC status carrying the API error code as an
+// ErrorInfo reason and the problem extensions as metadata. Errors that
+// already are statuses, such as a failed stream Send, pass through.
+func statusFor(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	e := apierror.From(err)
+	code, ok := httpCodes[e.HTTPStatus()]
+	if !ok {
+		code = codes.Internal
+	}
+
+	info := &errdetails.ErrorInfo{Reason: string(e.Code), Domain: errorDomain}
+	for k, v := range e.Extensions {
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
+		}
+		info.Metadata[k] = fmt.Sprint(v)
+	}
+	st, detErr := status.New(code, errorMessage(e)).WithDetails(info)
+	if detErr != nil {
+		return status.Error(code, errorMessage(e))
+	}
+	return st.Err()
+}
@@ -0,0 +1,186 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/ratelimit"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key mirroring the X-Request-ID header.
+const requestIDKey = "x-request-id"
+
+// withLogger stores a request-scoped logger in ctx, as the HTTP logging
+// middleware does, and echoes the request ID in the response header.
+func withLogger(ctx context.Context, method string) (context.Context, *log.Entry) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := logging.RequestIDOrNew(first(md, requestIDKey))
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	entry := log.WithFields(log.Fields{"request_id": id, "grpc_method": method})
+	return logging.WithLogger(ctx, entry), entry
+}
+
+func logCompletion(entry *log.Entry, start time.Time, err error) {
+	code := status.Code(err)
+	access := entry.WithFields(log.Fields{"grpc_code": code.String(), "latency_ms": time.Since(start).Milliseconds()})
+	switch code {
+	case codes.OK:
+		access.Info("rpc completed")
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded:
+		access.WithError(err).Error("rpc completed")
+	default:
+		access.WithError(err).Warn("rpc completed")
+	}
+}
+
+func unaryLogging(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, entry := withLogger(ctx, info.FullMethod)
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCompletion(entry, start, err)
+	return resp, err
+}
+
+func streamLogging(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, entry := withLogger(ss.Context(), info.FullMethod)
+	start := time.Now()
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logCompletion(entry, start, err)
+	return err
+}
+
+func recovered(ctx context.Context, r interface{}) error {
+	logging.FromContext(ctx).WithField("stack", string(debug.Stack())).Errorf("panic: %v", r)
+	return statusFor(apierror.Wrap(apierror.CodeInternal, fmt.Errorf("panic: %v", r), "internal error"))
+}
+
+func unaryRecovery(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func streamRecovery(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ss.Context(), r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// authenticate applies the REST API key rules to the method's quota kind and
+// returns ctx carrying the key for further charges.
+func authenticate(ctx context.Context, a *auth.Authenticator, method string) (context.Context, error) {
+	if !a.Enabled() {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	key, q, err := a.Check(ctx, auth.Key(first(md, "x-api-key"), first(md, "authorization")), methodKinds[method])
+	if q.Limit > 0 {
+		grpc.SetHeader(ctx, metadata.Pairs("x-quota-limit", fmt.Sprint(q.Limit), "x-quota-remaining", fmt.Sprint(max(q.Limit-q.Used, 0))))
+	}
+	if err != nil {
+		return ctx, statusFor(err)
+	}
+	return a.WithKey(ctx, key), nil
+}
+
+func unaryAuth(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// rateLimit applies the REST rate limit policy, with each RPC counted under
+// the route it mirrors. Peer addresses stand in for the client IP.
+func rateLimit(ctx context.Context, l ratelimit.Limiter, p ratelimit.Policy, method string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	req := ratelimit.Request{Key: auth.Key(first(md, "x-api-key"), first(md, "authorization"))}
+	if r, ok := methodRoutes[method]; ok {
+		req.Method, req.Route = r.method, r.path
+	}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		req.IP = pr.Addr.String()
+		if host, _, err := net.SplitHostPort(req.IP); err == nil {
+			req.IP = host
+		}
+	}
+
+	res, ok := ratelimit.Check(ctx, l, p, req)
+	if res != nil {
+		grpc.SetHeader(ctx, metadata.Pairs(
+			"x-ratelimit-limit", fmt.Sprint(res.Limit),
+			"x-ratelimit-remaining", fmt.Sprint(res.Remaining),
+		))
+	}
+	if !ok {
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", fmt.Sprint(ratelimit.RetryAfterSeconds(*res))))
+		return statusFor(ratelimit.Exceeded(*res))
+	}
+	return nil
+}
+
+func unaryRateLimit(l ratelimit.Limiter, p ratelimit.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := rateLimit(ctx, l, p, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamRateLimit(l ratelimit.Limiter, p ratelimit.Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(ss.Context(), l, p, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
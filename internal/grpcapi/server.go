@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+
+	towardsv1 "github.com/publicthrone547/towards_project/api/towards/v1"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/ratelimit"
+	"google.golang.org/grpc"
+)
+
+// methodKinds maps each RPC to the API key quota it is counted against, as
+// the matching REST routes are.
+var methodKinds = map[string]string{
+	towardsv1.Towards_GetWeather_FullMethodName:    auth.KindWeather,
+	towardsv1.Towards_CompareCities_FullMethodName: auth.KindWeather,
+	towardsv1.Towards_Ask_FullMethodName:           auth.KindAI,
+	towardsv1.Towards_Improve_FullMethodName:       auth.KindAI,
+}
+
+// methodRoutes maps each RPC to the REST route whose rate limit it shares.
+// CompareCities fans out like a multi-city GraphQL query.
+var methodRoutes = map[string]struct{ method, path string }{
+	towardsv1.Towards_GetWeather_FullMethodName:    {http.MethodGet, "/weather"},
+	towardsv1.Towards_CompareCities_FullMethodName: {http.MethodPost, "/graphql"},
+	towardsv1.Towards_Ask_FullMethodName:           {http.MethodPost, "/ask"},
+	towardsv1.Towards_Improve_FullMethodName:       {http.MethodPost, "/improve"},
+}
+
+// Server implements the Towards gRPC service on top of the same
+// handlers.Server methods the REST handlers use.
+type Server struct {
+	towardsv1.UnimplementedTowardsServer
+	s *handlers.Server
+}
+
+// NewServer returns a gRPC server with the Towards service registered behind
+// logging, panic recovery, rate limit and API key interceptors. l and p are
+// the limiter and policy the REST routes use, so both share buckets.
+func NewServer(s *handlers.Server, a *auth.Authenticator, l ratelimit.Limiter, p ratelimit.Policy, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unaryLogging, unaryRecovery, unaryRateLimit(l, p), unaryAuth(a)),
+		grpc.ChainStreamInterceptor(streamLogging, streamRecovery, streamRateLimit(l, p), streamAuth(a)),
+	)
+	gs := grpc.NewServer(opts...)
+	towardsv1.RegisterTowardsServer(gs, &Server{s: s})
+	return gs
+}
+
+func (g *Server) GetWeather(ctx context.Context, req *towardsv1.GetWeatherRequest) (*towardsv1.Weather, error) {
+	w, err := g.s.Weather(ctx, req.GetCity(), req.GetDate())
+	if err != nil {
+		return nil, statusFor(err)
+	}
+	return weatherToProto(handlers.WeatherV2(w)), nil
+}
+
+func (g *Server) CompareCities(ctx context.Context, req *towardsv1.CompareCitiesRequest) (*towardsv1.CompareCitiesResponse, error) {
+	results, err := g.s.CompareCities(ctx, req.GetCities(), req.GetDate())
+	if err != nil {
+		return nil, statusFor(err)
+	}
+	out := &towardsv1.CompareCitiesResponse{}
+	for _, r := range results {
+		res := &towardsv1.CityResult{City: r.City}
+		if r.Err != nil {
+			e := apierror.From(r.Err)
+			res.ErrorCode = string(e.Code)
+			res.Error = errorMessage(e)
+		} else {
+			res.Weather = weatherToProto(handlers.WeatherV2(r.Weather))
+		}
+		out.Results = append(out.Results, res)
+	}
+	return out, nil
+}
+
+func (g *Server) Ask(req *towardsv1.AskRequest, stream grpc.ServerStreamingServer[towardsv1.AskChunk]) error {
+	if req.GetPrompt() == "" {
+		return statusFor(missing("prompt required"))
+	}
+	err := g.s.AskStream(stream.Context(), req.GetInstruction(), req.GetPrompt(), func(chunk string) error {
+		return stream.Send(&towardsv1.AskChunk{Text: chunk})
+	})
+	if err != nil {
+		return statusFor(err)
+	}
+	return nil
+}
+
+func (g *Server) Improve(ctx context.Context, req *towardsv1.ImproveRequest) (*towardsv1.ImproveResponse, error) {
+	if req.GetCity() == "" {
+		return nil, statusFor(missing("city required"))
+	}
+	reply, err := g.s.Improve(ctx, req.GetCity(), req.GetDate(), req.GetMetrics().AsMap())
+	if err != nil {
+		return nil, statusFor(err)
+	}
+	return &towardsv1.ImproveResponse{Suggestions: reply}, nil
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+type CreateAPIKeyRequest struct {
+	Name         string `json:"name" binding:"required"`
+	AIQuota      int    `json:"ai_quota"`
+	WeatherQuota int    `json:"weather_quota"`
+}
+
+type CreateAPIKeyResponse struct {
+	models.APIKey
+	// Key is the plaintext key; it is only ever returned here.
+	Key string `json:"key"`
+}
+
+func (s *Server) CreateAPIKey(c *gin.Context) {
+	if s.apiKeys == nil {
+		apierror.Respond(c, notConfigured("api keys"))
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "name required"))
+		return
+	}
+	if req.AIQuota < 0 || req.WeatherQuota < 0 {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "quotas must not be negative"))
+		return
+	}
+
+	k := models.APIKey{Name: req.Name, AIQuota: req.AIQuota, WeatherQuota: req.WeatherQuota}
+	plain, err := s.apiKeys.Create(c.Request.Context(), &k)
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to create api key"))
+		return
+	}
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: k, Key: plain})
+}
+
+func (s *Server) ListAPIKeys(c *gin.Context) {
+	if s.apiKeys == nil {
+		apierror.Respond(c, notConfigured("api keys"))
+		return
+	}
+
+	keys, err := s.apiKeys.List(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to list api keys"))
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (s *Server) RevokeAPIKey(c *gin.Context) {
+	if s.apiKeys == nil {
+		apierror.Respond(c, notConfigured("api keys"))
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "invalid api key id"))
+		return
+	}
+
+	err = s.apiKeys.Revoke(c.Request.Context(), id)
+	if errors.Is(err, auth.ErrNotFound) {
+		apierror.Respond(c, apierror.New(apierror.CodeNotFound, "api key not found or already revoked"))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to revoke api key"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) GetAPIKeyUsage(c *gin.Context) {
+	if s.apiKeys == nil {
+		apierror.Respond(c, notConfigured("api keys"))
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "invalid api key id"))
+		return
+	}
+	days := 30
+	if d, err := strconv.Atoi(c.Query("days")); err == nil && d > 0 && d <= 365 {
+		days = d
+	}
+
+	usage, err := s.apiKeys.Usage(c.Request.Context(), id, days)
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to load usage"))
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
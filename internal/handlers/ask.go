@@ -1,11 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/publicthrone547/towards_project/internal/ai"
-	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/apierror"
 )
 
 type AskRequest struct {
@@ -17,20 +17,41 @@ type AskResponse struct {
 	Reply string `json:"reply"`
 }
 
-func AskHandler(c *gin.Context) {
+func (s *Server) AskHandler(c *gin.Context) {
 	var req AskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "prompt required"})
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInvalidRequest, err, "prompt required"))
 		return
 	}
 
-	cfg := config.Load()
-	key := cfg.GeminiAPIKey
-
-	reply, err := ai.AskGemini(key, req.Instruction, req.Prompt)
+	reply, err := s.Ask(c.Request.Context(), req.Instruction, req.Prompt)
 	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "ai request failed", "detail": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, AskResponse{Reply: reply})
 }
+
+// Ask sends prompt to the city improvement assistant. instruction replaces
+// the default system instruction when set.
+func (s *Server) Ask(ctx context.Context, instruction, prompt string) (string, error) {
+	reply, err := s.ai.Ask(ctx, instruction, prompt)
+	if err != nil {
+		return "", aiUpstreamError(err)
+	}
+	return reply, nil
+}
+
+// AskStream is Ask with the reply passed to fn chunk by chunk. Errors
+// returned by fn are passed through unchanged.
+func (s *Server) AskStream(ctx context.Context, instruction, prompt string, fn func(chunk string) error) error {
+	var fnErr error
+	err := s.ai.AskStream(ctx, instruction, prompt, func(chunk string) error {
+		fnErr = fn(chunk)
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return aiUpstreamError(err)
+	}
+	return err
+}
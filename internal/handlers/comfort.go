@@ -0,0 +1,110 @@
+package handlers
+
+import "math"
+
+// thermalIndices holds apparent-temperature values derived from air
+// temperature (°C), relative humidity (%) and wind speed (km/h, as returned by
+// Visual Crossing with unitGroup=metric).
+type thermalIndices struct {
+	FeelsLike float64
+	HeatIndex float64
+	WindChill float64
+	Humidex   float64
+	UTCI      float64
+}
+
+func computeThermalIndices(tempC, humidity, windKmh float64) thermalIndices {
+	ti := thermalIndices{
+		HeatIndex: heatIndex(tempC, humidity),
+		WindChill: windChill(tempC, windKmh),
+		Humidex:   humidex(tempC, humidity),
+		UTCI:      approxUTCI(tempC, humidity, windKmh),
+	}
+
+	ti.FeelsLike = tempC
+	if tempC >= 27 && humidity >= 40 {
+		ti.FeelsLike = ti.HeatIndex
+	} else if tempC <= 10 && windKmh > 4.8 {
+		ti.FeelsLike = ti.WindChill
+	}
+	return ti
+}
+
+// heatIndex uses the NOAA Rothfusz regression (with Steadman's simple formula
+// below 80°F, as NOAA does) and returns °C.
+func heatIndex(tempC, humidity float64) float64 {
+	t := tempC*9/5 + 32
+	rh := humidity
+
+	simple := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (simple+t)/2 < 80 {
+		return round1((simple - 32) * 5 / 9)
+	}
+
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh - 0.22475541*t*rh -
+		0.00683783*t*t - 0.05481717*rh*rh + 0.00122874*t*t*rh +
+		0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	if rh < 13 && t >= 80 && t <= 112 {
+		hi -= ((13 - rh) / 4) * math.Sqrt((17-math.Abs(t-95))/17)
+	} else if rh > 85 && t >= 80 && t <= 87 {
+		hi += ((rh - 85) / 10) * ((87 - t) / 5)
+	}
+	return round1((hi - 32) * 5 / 9)
+}
+
+// windChill uses the Environment Canada / NWS formula. It is only defined for
+// temperatures at or below 10°C and wind above 4.8 km/h; otherwise the air
+// temperature is returned unchanged.
+func windChill(tempC, windKmh float64) float64 {
+	if tempC > 10 || windKmh <= 4.8 {
+		return round1(tempC)
+	}
+	v := math.Pow(windKmh, 0.16)
+	return round1(13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v)
+}
+
+// humidex is the Canadian humidity index, computed from the vapour pressure.
+func humidex(tempC, humidity float64) float64 {
+	return round1(tempC + 0.5555*(vapourPressure(tempC, humidity)-10))
+}
+
+// approxUTCI approximates the Universal Thermal Climate Index with Steadman's
+// apparent temperature (shade, no radiation term). The full UTCI operational
+// procedure is a ~200 term polynomial that also needs mean radiant
+// temperature, which we do not have.
+func approxUTCI(tempC, humidity, windKmh float64) float64 {
+	windMs := windKmh / 3.6
+	return round1(tempC + 0.33*vapourPressure(tempC, humidity) - 0.70*windMs - 4.0)
+}
+
+// vapourPressure returns the water vapour pressure in hPa.
+func vapourPressure(tempC, humidity float64) float64 {
+	return humidity / 100 * 6.105 * math.Exp(17.27*tempC/(237.7+tempC))
+}
+
+// lifeComfortIndex averages the thermal score with the air, traffic and crime
+// components into a single 0..100 value.
+func lifeComfortIndex(thermalScore float64, airScore, trafficScore, crimeScore int) float64 {
+	trafficComfort := 100 - trafficScore
+	if trafficComfort < 0 {
+		trafficComfort = 0
+	}
+	crimeComfort := 100 - crimeScore
+	if crimeComfort < 0 {
+		crimeComfort = 0
+	}
+
+	total := (thermalScore + float64(airScore) + float64(trafficComfort) + float64(crimeComfort)) / 4.0
+	if total < 0 {
+		total = 0
+	}
+	if total > 100 {
+		total = 100
+	}
+	return total
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/publicthrone547/towards_project/internal/apierror"
+)
+
+// maxCompareCities bounds the upstream fan-out of one comparison.
+const maxCompareCities = 10
+
+// CityComparison is one city's outcome in CompareCities; exactly one of
+// Weather and Err is set.
+type CityComparison struct {
+	City    string
+	Weather *WeatherResponse
+	Err     error
+}
+
+// CompareCities loads weather for each city concurrently, without AI
+// forecasts. Cities that loaded come first by descending comfort index,
+// followed by the failures in request order. Only invalid input fails the
+// whole call.
+func (s *Server) CompareCities(ctx context.Context, cities []string, date string) ([]CityComparison, error) {
+	if len(cities) == 0 {
+		return nil, apierror.New(apierror.CodeMissingParameter, "at least one city required")
+	}
+	if len(cities) > maxCompareCities {
+		return nil, apierror.New(apierror.CodeInvalidRequest, "at most %d cities can be compared, got %d", maxCompareCities, len(cities))
+	}
+	for _, city := range cities {
+		if city == "" {
+			return nil, apierror.New(apierror.CodeInvalidRequest, "city names must not be empty")
+		}
+	}
+
+	out := make([]CityComparison, len(cities))
+	var wg sync.WaitGroup
+	for i, city := range cities {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, _, err := s.loadWeather(ctx, city, date)
+			out[i] = CityComparison{City: city, Weather: w, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		return a.Err == nil && a.Weather.LifeComfortIdx > b.Weather.LifeComfortIdx
+	})
+	return out, nil
+}
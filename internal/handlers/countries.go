@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/countries"
+)
+
+func (s *Server) GetCountry(c *gin.Context) {
+	p, err := s.CountryProfile(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// CountryProfile returns the World Bank profile of the country with the
+// ISO 3166-1 alpha-2 or alpha-3 code.
+func (s *Server) CountryProfile(ctx context.Context, code string) (*countries.Profile, error) {
+	if !countries.ValidCode(code) {
+		return nil, apierror.New(apierror.CodeInvalidRequest, "country code must be 2 or 3 letters, got %q", code)
+	}
+	code = strings.ToUpper(code)
+
+	cacheKey := "profile:" + code
+	if v, ok := s.cache.Get(cacheKey); ok {
+		return v.(*countries.Profile), nil
+	}
+	p, err := s.countries.Profile(ctx, code)
+	if err != nil {
+		return nil, countryUpstreamError(code, err)
+	}
+	s.cache.Set(cacheKey, p)
+	return p, nil
+}
@@ -1,11 +1,12 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"math"
-	"net/http"
 	"time"
+
+	"github.com/publicthrone547/towards_project/internal/apierror"
 )
 
 type quakeEvent struct {
@@ -15,25 +16,55 @@ type quakeEvent struct {
 	Distance float64
 }
 
-func fetchEarthquakeRisk(lat, lon float64, radiusKm int, periodYears int) (float64, int, float64, []map[string]interface{}, error) {
-	end := time.Now().UTC()
-	start := end.AddDate(-periodYears, 0, 0)
-	url := fmt.Sprintf("https://earthquake.usgs.gov/fdsnws/event/1/query.geojson?starttime=%s&endtime=%s&latitude=%.6f&longitude=%.6f&maxradiuskm=%d&minmagnitude=3&format=geojson",
-		start.Format("2006-01-02"), end.Format("2006-01-02"), lat, lon, radiusKm)
+type quakeSummary struct {
+	Risk           float64
+	Count          int
+	MaxMag         float64
+	MaxMagLastWeek float64
+	Recent         []map[string]interface{}
+}
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(url)
+// Earthquakes returns the earthquake summary around city, fetching only the
+// weather timeline needed to locate it. It is nil when the provider returned
+// no coordinates.
+func (s *Server) Earthquakes(ctx context.Context, city string) (*EarthquakeMetrics, error) {
+	if city == "" {
+		return nil, apierror.New(apierror.CodeMissingParameter, "city required")
+	}
+	_, body, _, err := s.fetchWeather(ctx, city, "")
 	if err != nil {
-		return 0, 0, 0, nil, err
+		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, 0, nil, fmt.Errorf("usgs returned %s", resp.Status)
+	return s.earthquakes(ctx, body)
+}
+
+// earthquakes summarizes earthquakes within 100 km of the location in a
+// weather timeline response over 30 years.
+func (s *Server) earthquakes(ctx context.Context, body map[string]interface{}) (*EarthquakeMetrics, error) {
+	lat, lon, ok := coordinates(body)
+	if !ok {
+		return nil, nil
 	}
+	q, err := s.fetchEarthquakeRisk(ctx, lat, lon, 100, 30)
+	if err != nil {
+		return nil, err
+	}
+	eq := &EarthquakeMetrics{Risk: q.Risk, Count: q.Count, MaxMag: q.MaxMag, MaxMag7d: q.MaxMagLastWeek, Recent: []Earthquake{}}
+	for _, m := range q.Recent {
+		eq.Recent = append(eq.Recent, earthquakeFromMap(m))
+	}
+	return eq, nil
+}
+
+func (s *Server) fetchEarthquakeRisk(ctx context.Context, lat, lon float64, radiusKm int, periodYears int) (quakeSummary, error) {
+	end := time.Now().UTC()
+	start := end.AddDate(-periodYears, 0, 0)
+	url := endpoint(s.cfg.USGS.BaseURL, fmt.Sprintf("query.geojson?starttime=%s&endtime=%s&latitude=%.6f&longitude=%.6f&maxradiuskm=%d&minmagnitude=3&format=geojson",
+		start.Format("2006-01-02"), end.Format("2006-01-02"), lat, lon, radiusKm))
 
 	var data map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return 0, 0, 0, nil, err
+	if err := s.getJSON(ctx, url, nil, &data); err != nil {
+		return quakeSummary{}, fmt.Errorf("usgs: %w", err)
 	}
 
 	features, _ := data["features"].([]interface{})
@@ -68,6 +99,7 @@ func fetchEarthquakeRisk(lat, lon float64, radiusKm int, periodYears int) (float
 	var totalWeightedEnergy float64
 	now := time.Now()
 	recentCount := 0
+	maxMagLastWeek := 0.0
 	for i := range features {
 		if f, ok := features[i].(map[string]interface{}); ok {
 			props, _ := f["properties"].(map[string]interface{})
@@ -87,6 +119,9 @@ func fetchEarthquakeRisk(lat, lon float64, radiusKm int, periodYears int) (float
 			decay := math.Exp(-days/730.0)
 			energy := math.Pow(10.0, 1.5*mag)
 			totalWeightedEnergy += energy * decay
+			if days <= 7.0 && mag > maxMagLastWeek {
+				maxMagLastWeek = mag
+			}
 			if days <= 365.0*5.0 {
 				if mag >= 3.0 {
 					recentCount++
@@ -128,5 +163,11 @@ func fetchEarthquakeRisk(lat, lon float64, radiusKm int, periodYears int) (float
 		score = 100
 	}
 
-	return score, count, maxMag, recent, nil
+	return quakeSummary{
+		Risk:           score,
+		Count:          count,
+		MaxMag:         maxMag,
+		MaxMagLastWeek: maxMagLastWeek,
+		Recent:         recent,
+	}, nil
 }
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/countries"
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+	"github.com/publicthrone547/towards_project/internal/secrets"
+)
+
+// errorDetail renders err for a response body with secrets redacted; upstream
+// errors often embed the request URL, which may carry an API key.
+func errorDetail(err error) string {
+	return secrets.Redact(err.Error())
+}
+
+// statusError reports a non-200 upstream response.
+type statusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected status " + e.Status
+}
+
+// decodeError reports an upstream body that is not the expected JSON.
+type decodeError struct {
+	Err error
+}
+
+func (e *decodeError) Error() string {
+	return "decode response: " + e.Err.Error()
+}
+
+// weatherUpstreamError maps a failed Visual Crossing call to an API error.
+// Visual Crossing answers 400 for locations it cannot resolve.
+func weatherUpstreamError(city string, err error) *apierror.Error {
+	var se *statusError
+	var de *decodeError
+	var open *httpclient.CircuitOpenError
+	switch {
+	case errors.As(err, &se) && (se.StatusCode == http.StatusBadRequest || se.StatusCode == http.StatusNotFound):
+		return apierror.Wrap(apierror.CodeCityNotFound, err, "weather provider could not resolve %q", city)
+	case errors.As(err, &se):
+		return apierror.Wrap(apierror.CodeUpstreamWeatherUnavailable, err, "weather provider returned %s", se.Status)
+	case errors.As(err, &de):
+		return apierror.Wrap(apierror.CodeUpstreamWeatherInvalid, err, "%s", errorDetail(de.Err))
+	case errors.As(err, &open):
+		return &apierror.Error{Code: apierror.CodeUpstreamWeatherUnavailable, Status: http.StatusServiceUnavailable, Detail: errorDetail(err), Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return apierror.Wrap(apierror.CodeUpstreamTimeout, err, "weather provider timed out")
+	default:
+		return apierror.Wrap(apierror.CodeUpstreamWeatherUnavailable, err, "%s", errorDetail(err))
+	}
+}
+
+// aiUpstreamError maps a failed Gemini call to an API error, distinguishing
+// safety blocks and quota exhaustion from outages. API errors, such as the
+// caller's exhausted AI quota, pass through.
+func aiUpstreamError(err error) *apierror.Error {
+	var blocked *ai.BlockedError
+	var apiErr *ai.APIError
+	var open *httpclient.CircuitOpenError
+	var e *apierror.Error
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.As(err, &blocked):
+		return apierror.Wrap(apierror.CodeAIContentBlocked, err, "%s", blocked.Error()).With("block_reason", blocked.Reason)
+	case errors.Is(err, ai.ErrEmptyResponse):
+		return apierror.Wrap(apierror.CodeAIEmptyResponse, err, "the model returned no text")
+	case errors.As(err, &apiErr) && apiErr.RateLimited():
+		return apierror.Wrap(apierror.CodeUpstreamAIRateLimited, err, "AI provider quota exhausted, retry later")
+	case errors.As(err, &apiErr):
+		return apierror.Wrap(apierror.CodeUpstreamAIUnavailable, err, "%s", errorDetail(err))
+	case errors.As(err, &open):
+		return &apierror.Error{Code: apierror.CodeUpstreamAIUnavailable, Status: http.StatusServiceUnavailable, Detail: errorDetail(err), Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return apierror.Wrap(apierror.CodeUpstreamTimeout, err, "AI provider timed out")
+	default:
+		return apierror.Wrap(apierror.CodeUpstreamAIUnavailable, err, "%s", errorDetail(err))
+	}
+}
+
+// countryUpstreamError maps a failed World Bank call to an API error.
+func countryUpstreamError(code string, err error) *apierror.Error {
+	var se *countries.StatusError
+	var open *httpclient.CircuitOpenError
+	switch {
+	case errors.Is(err, countries.ErrNotFound):
+		return apierror.Wrap(apierror.CodeCountryNotFound, err, "no country with code %q", code)
+	case errors.As(err, &se):
+		return apierror.Wrap(apierror.CodeUpstreamCountryUnavailable, err, "country data provider returned %s", se.Status)
+	case errors.As(err, &open):
+		return &apierror.Error{Code: apierror.CodeUpstreamCountryUnavailable, Status: http.StatusServiceUnavailable, Detail: errorDetail(err), Err: err}
+	case errors.Is(err, context.DeadlineExceeded):
+		return apierror.Wrap(apierror.CodeUpstreamTimeout, err, "country data provider timed out")
+	default:
+		return apierror.Wrap(apierror.CodeUpstreamCountryUnavailable, err, "%s", errorDetail(err))
+	}
+}
+
+// notConfigured is returned by endpoints whose backing store is absent.
+func notConfigured(feature string) *apierror.Error {
+	return apierror.New(apierror.CodeNotConfigured, "%s are not configured", feature)
+}
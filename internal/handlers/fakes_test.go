@@ -0,0 +1,303 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/cache"
+	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+)
+
+// fakeUpstream is an httptest server standing in for one external API. Its
+// handler can be swapped per test and every request it receives is kept.
+type fakeUpstream struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handler  http.HandlerFunc
+	requests []*recordedRequest
+}
+
+type recordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   string
+}
+
+func newFakeUpstream(t *testing.T, handler http.HandlerFunc) *fakeUpstream {
+	f := &fakeUpstream{handler: handler}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		f.requests = append(f.requests, &recordedRequest{Method: r.Method, URL: r.URL, Header: r.Header.Clone(), Body: string(body)})
+		h := f.handler
+		f.mu.Unlock()
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		h(w, r)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// set replaces the handler for the rest of the test.
+func (f *fakeUpstream) set(h http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handler = h
+}
+
+func (f *fakeUpstream) calls() []*recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*recordedRequest(nil), f.requests...)
+}
+
+// fakes holds one fake per upstream.
+type fakes struct {
+	weather   *fakeUpstream
+	usgs      *fakeUpstream
+	countries *fakeUpstream
+	worldBank *fakeUpstream
+	nominatim *fakeUpstream
+	gemini    *fakeUpstream
+}
+
+func newFakes(t *testing.T) *fakes {
+	return &fakes{
+		weather:   newFakeUpstream(t, timelineHandler),
+		usgs:      newFakeUpstream(t, jsonHandler(http.StatusOK, usgsBody)),
+		countries: newFakeUpstream(t, jsonHandler(http.StatusOK, restCountriesBody)),
+		worldBank: newFakeUpstream(t, worldBankHandler),
+		nominatim: newFakeUpstream(t, nominatimHandler),
+		gemini:    newFakeUpstream(t, geminiHandler("Sunny and mild, take sunglasses.")),
+	}
+}
+
+// localOnly fails requests to any host other than the fakes, so a test can
+// never reach the network. It does not rewrite hosts: requests get to a fake
+// only through the base URLs set by testConfig.
+type localOnly struct {
+	hosts map[string]bool
+}
+
+func (lo localOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !lo.hosts[req.URL.Host] {
+		return nil, fmt.Errorf("unexpected upstream request to %s", req.URL)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (f *fakes) all() []*fakeUpstream {
+	return []*fakeUpstream{f.weather, f.usgs, f.countries, f.worldBank, f.nominatim, f.gemini}
+}
+
+// testConfig returns a valid configuration pointing every upstream at its
+// fake, with retries, breakers and request spacing off so failures surface
+// on the first attempt. The fakes serve the API paths under a prefix, like a
+// gateway would.
+func testConfig(f *fakes) *config.Config {
+	cfg := config.Default()
+	cfg.Weather.APIKey = "vc-test-key"
+	cfg.AI.APIKey = "gemini-test-key"
+	cfg.Weather.BaseURL = f.weather.URL + "/vc/"
+	cfg.USGS.BaseURL = f.usgs.URL + "/fdsnws/event/1"
+	cfg.Countries.BaseURL = f.countries.URL + "/v3.1"
+	cfg.WorldBank.BaseURL = f.worldBank.URL + "/v2/"
+	cfg.Geocoding.BaseURL = f.nominatim.URL
+	cfg.AI.BaseURL = f.gemini.URL + "/v1beta"
+	cfg.Outbound.Retries = 0
+	cfg.Outbound.BreakerThreshold = 0
+	cfg.Geocoding.MinInterval = 0
+	return cfg
+}
+
+// newTestServer wires a Server to the fakes the way cmd/server does, with
+// an in-memory cache and no database.
+func newTestServer(t *testing.T, f *fakes, cfg *config.Config) *handlers.Server {
+	t.Helper()
+	hosts := map[string]bool{}
+	for _, u := range f.all() {
+		hosts[strings.TrimPrefix(u.URL, "http://")] = true
+	}
+	client := &http.Client{Transport: httpclient.NewTransport(
+		localOnly{hosts: hosts},
+		handlers.OutboundOptions(cfg),
+		handlers.Upstreams(cfg),
+	)}
+	s, err := handlers.NewServer(cfg, nil, client, cache.New(time.Minute), ai.NewClient(cfg.AI, client, nil))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+func newRouter(s *handlers.Server) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/weather", s.GetWeather)
+	r.POST("/ask", s.AskHandler)
+	r.POST("/improve", s.ImproveHandler)
+	r.GET("/countries/:code", s.GetCountry)
+	r.POST("/graphql", s.GraphQL)
+	return r
+}
+
+// do sends a request to r and decodes the JSON response into a map.
+func do(t *testing.T, r http.Handler, method, target string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = strings.NewReader(string(data))
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("%s %s: decode response %q: %v", method, target, w.Body.String(), err)
+	}
+	return w.Code, out
+}
+
+func jsonHandler(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+// nominatimHandler answers both the search and the reverse endpoints.
+func nominatimHandler(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/reverse") {
+		jsonHandler(http.StatusOK, `{"address":{"country":"France"}}`)(w, r)
+		return
+	}
+	jsonHandler(http.StatusOK, `[{"display_name":"Paris, France","extratags":{"population":"2102650"}}]`)(w, r)
+}
+
+// worldBankHandler answers country metadata and indicator queries for France
+// and the "Invalid value" message for any other code. Indicator rows come
+// newest first, some years still null, like the real API.
+func worldBankHandler(w http.ResponseWriter, r *http.Request) {
+	code := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v2/country/"), "/", 2)[0]
+	switch {
+	case !strings.EqualFold(code, "fra") && !strings.EqualFold(code, "fr"):
+		jsonHandler(http.StatusOK, `[{"message":[{"id":"120","key":"Invalid value","value":"The provided parameter value is not valid"}]}]`)(w, r)
+	case strings.Contains(r.URL.Path, "/indicator/"):
+		jsonHandler(http.StatusOK, worldBankIndicatorsBody)(w, r)
+	default:
+		jsonHandler(http.StatusOK, worldBankCountryBody)(w, r)
+	}
+}
+
+// timelineHandler serves timelineBody with only the sections named by the
+// include parameter, as Visual Crossing does; without one it sends them all.
+func timelineHandler(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(timelineBody), &body); err != nil {
+		panic(err)
+	}
+	if include := r.URL.Query().Get("include"); include != "" {
+		sections := map[string]bool{}
+		for _, s := range strings.Split(include, ",") {
+			sections[s] = true
+		}
+		if !sections["current"] {
+			delete(body, "currentConditions")
+		}
+		if !sections["days"] {
+			delete(body, "days")
+		} else if !sections["hours"] {
+			for _, d := range body["days"].([]interface{}) {
+				delete(d.(map[string]interface{}), "hours")
+			}
+		}
+	}
+	data, _ := json.Marshal(body)
+	jsonHandler(http.StatusOK, string(data))(w, r)
+}
+
+// geminiHandler answers generateContent with text.
+func geminiHandler(text string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"candidates": []map[string]interface{}{
+				{"content": map[string]interface{}{"parts": []map[string]string{{"text": text}}}, "finishReason": "STOP"},
+			},
+			"usageMetadata": map[string]int{"promptTokenCount": 10, "candidatesTokenCount": 5},
+		})
+		jsonHandler(http.StatusOK, string(body))(w, r)
+	}
+}
+
+const timelineBody = `{
+  "resolvedAddress": "Paris, Île-de-France, France",
+  "latitude": 48.8567,
+  "longitude": 2.3510,
+  "days": [{
+    "datetime": "2026-01-10",
+    "tempmax": 21.0,
+    "tempmin": 12.0,
+    "temp": 17.0,
+    "humidity": 60.0,
+    "windspeed": 10.0,
+    "pressure": 1015.0,
+    "conditions": "Clear",
+    "hours": [
+      {"datetime": "09:00:00", "temp": 15.0, "feelslike": 15.0, "humidity": 70.0, "windspeed": 8.0, "conditions": "Clear"},
+      {"datetime": "15:00:00", "temp": 21.0, "feelslike": 21.0, "humidity": 50.0, "windspeed": 12.0, "conditions": "Clear"}
+    ]
+  }],
+  "currentConditions": {"temp": 19.0, "humidity": 55.0, "windspeed": 9.0, "conditions": "Sunny"}
+}`
+
+const usgsBody = `{"features": [
+  {"properties": {"mag": 4.2, "place": "10 km N of Somewhere", "time": 1700000000000}},
+  {"properties": {"mag": 3.1, "place": "5 km S of Elsewhere", "time": 1600000000000}}
+]}`
+
+const restCountriesBody = `[{"cca3": "FRA", "population": 68000000, "area": 551695}]`
+
+const worldBankCountryBody = `[{"page": 1}, [{
+  "id": "FRA", "iso2Code": "FR", "name": "France",
+  "region": {"id": "ECS", "value": "Europe & Central Asia "},
+  "incomeLevel": {"id": "HIC", "value": "High income"},
+  "capitalCity": "Paris"
+}]]`
+
+const worldBankIndicatorsBody = `[{"page": 1}, [
+  {"indicator": {"id": "NY.GDP.MKTP.CD"}, "date": "2025", "value": null},
+  {"indicator": {"id": "NY.GDP.MKTP.CD"}, "date": "2024", "value": 3030000000000},
+  {"indicator": {"id": "NY.GDP.PCAP.CD"}, "date": "2025", "value": null},
+  {"indicator": {"id": "NY.GDP.PCAP.CD"}, "date": "2024", "value": 44690.9},
+  {"indicator": {"id": "NY.GDP.PCAP.CD"}, "date": "2023", "value": 44460.8},
+  {"indicator": {"id": "SP.DYN.LE00.IN"}, "date": "2024", "value": null},
+  {"indicator": {"id": "SP.DYN.LE00.IN"}, "date": "2023", "value": 83.3},
+  {"indicator": {"id": "SL.UEM.TOTL.ZS"}, "date": "2024", "value": 7.4},
+  {"indicator": {"id": "EN.GHG.CO2.PC.CE.AR5"}, "date": "2024", "value": null},
+  {"indicator": {"id": "EN.GHG.CO2.PC.CE.AR5"}, "date": "2023", "value": null},
+  {"indicator": {"id": "EN.GHG.CO2.PC.CE.AR5"}, "date": "2022", "value": 4.4},
+  {"indicator": {"id": "SP.URB.TOTL.IN.ZS"}, "date": "2024", "value": null}
+]]`
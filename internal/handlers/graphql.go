@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/secrets"
+)
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse mirrors graphql.Result for the OpenAPI spec.
+type GraphQLResponse struct {
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Errors []GraphQLError         `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQL executes a query against the city schema. Field errors are reported
+// in the response's errors list with the API error code as the "code"
+// extension, so the status is 200 unless the request itself is malformed.
+func (s *Server) GraphQL(c *gin.Context) {
+	var req GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInvalidRequest, err, "query required"))
+		return
+	}
+
+	// Each city selection costs its own round of upstream lookups, so a
+	// query is bounded like a REST comparison and counted as one weather
+	// call per city. The route already charged the first.
+	n := cityFanout(req.Query, req.OperationName)
+	if n > maxCompareCities {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "at most %d city selections per query, got %d", maxCompareCities, n))
+		return
+	}
+	for i := 1; i < n; i++ {
+		if err := auth.Charge(c.Request.Context(), auth.KindWeather); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+
+	schema, err := graphQLSchema()
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	res := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        c.Request.Context(),
+		RootObject:     map[string]interface{}{"server": s},
+	})
+	c.JSON(http.StatusOK, res)
+}
+
+// cityFanout counts the top-level city selections of the operation a query
+// runs, following fragments. Unparsable queries count 0 and are left to
+// graphql.Do to report.
+func cityFanout(query, operation string) int {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0
+	}
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok && f.Name != nil {
+			fragments[f.Name.Value] = f
+		}
+	}
+
+	var count func(set *ast.SelectionSet, seen map[string]bool) int
+	count = func(set *ast.SelectionSet, seen map[string]bool) int {
+		if set == nil {
+			return 0
+		}
+		n := 0
+		for _, sel := range set.Selections {
+			switch sel := sel.(type) {
+			case *ast.Field:
+				if sel.Name != nil && sel.Name.Value == "city" {
+					n++
+				}
+			case *ast.InlineFragment:
+				n += count(sel.SelectionSet, seen)
+			case *ast.FragmentSpread:
+				// A spread cycle is invalid; graphql.Do rejects it.
+				if f := fragments[sel.Name.Value]; f != nil && !seen[f.Name.Value] {
+					seen[f.Name.Value] = true
+					n += count(f.SelectionSet, seen)
+					delete(seen, f.Name.Value)
+				}
+			}
+		}
+		return n
+	}
+
+	most := 0
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok || (operation != "" && (op.Name == nil || op.Name.Value != operation)) {
+			continue
+		}
+		most = max(most, count(op.SelectionSet, map[string]bool{}))
+	}
+	return most
+}
+
+// cityResolver is the source of a City object. Upstream data is fetched on
+// first use and shared by every field that needs it, so a query only pays for
+// the upstreams behind the fields it selects: airPurity alone needs none,
+// comfort only Visual Crossing, earthquakes Visual Crossing and USGS.
+type cityResolver struct {
+	s    *Server
+	name string
+	date string
+
+	weatherOnce sync.Once
+	weather     *WeatherResponse
+	body        map[string]interface{}
+	day         time.Time
+	weatherErr  error
+
+	countryOnce sync.Once
+	country     string
+
+	forecastOnce sync.Once
+	forecast     interface{}
+	forecastErr  error
+}
+
+type graphQLCountry struct {
+	Name              string
+	GDPUSD            float64
+	Population        int64
+	PopulationDensity float64
+}
+
+func (r *cityResolver) loadWeather(ctx context.Context) (*WeatherResponse, error) {
+	r.weatherOnce.Do(func() {
+		r.weather, r.body, r.day, r.weatherErr = r.s.fetchWeather(ctx, r.name, r.date)
+	})
+	return r.weather, r.weatherErr
+}
+
+func (r *cityResolver) loadCountry(ctx context.Context) (string, error) {
+	if _, err := r.loadWeather(ctx); err != nil {
+		return "", err
+	}
+	r.countryOnce.Do(func() {
+		r.country = r.s.getCountryFromBody(ctx, r.body)
+	})
+	return r.country, nil
+}
+
+// loadForecast charges the caller's AI quota and asks Gemini once, however
+// many aliases select aiForecast.
+func (r *cityResolver) loadForecast(ctx context.Context) (interface{}, error) {
+	r.forecastOnce.Do(func() {
+		w, err := r.loadWeather(ctx)
+		if err != nil || !r.s.forecastWanted(r.day) {
+			r.forecastErr = err
+			return
+		}
+		if err := auth.Charge(ctx, auth.KindAI); err != nil {
+			r.forecastErr = err
+			return
+		}
+		text, err := r.s.aiForecast(ctx, w, r.day)
+		if err != nil {
+			r.forecastErr = aiUpstreamError(err)
+			return
+		}
+		r.forecast = text
+	})
+	return r.forecast, r.forecastErr
+}
+
+// graphQLError carries an API error code into the GraphQL errors list. Like
+// problem documents, the message is the redacted detail without the cause.
+type graphQLError struct {
+	err *apierror.Error
+}
+
+func (e graphQLError) Error() string {
+	if e.err.Detail != "" {
+		return secrets.Redact(e.err.Detail)
+	}
+	return e.err.Code.Title()
+}
+
+func (e graphQLError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.err.Code}
+	for k, v := range e.err.Extensions {
+		ext[k] = v
+	}
+	return ext
+}
+
+// cityField wraps a City field resolver: it passes the cityResolver in and
+// converts errors to graphQLError, logging server-side failures.
+func cityField(fn func(ctx context.Context, r *cityResolver) (interface{}, error)) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		v, err := fn(p.Context, p.Source.(*cityResolver))
+		if err != nil {
+			e := apierror.From(err)
+			if e.HTTPStatus() >= http.StatusInternalServerError {
+				logging.FromContext(p.Context).WithField("field", p.Info.FieldName).Warnf("graphql: %v", err)
+			}
+			return nil, graphQLError{e}
+		}
+		return v, nil
+	}
+}
+
+// weatherField resolves a City field computed from the weather alone.
+func weatherField(fn func(w *WeatherResponse) interface{}) graphql.FieldResolveFn {
+	return cityField(func(ctx context.Context, r *cityResolver) (interface{}, error) {
+		w, err := r.loadWeather(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return fn(w), nil
+	})
+}
+
+var graphQLSchema = sync.OnceValues(func() (graphql.Schema, error) {
+	scalars := func(names map[string]graphql.Output) graphql.Fields {
+		fields := graphql.Fields{}
+		for name, t := range names {
+			fields[name] = &graphql.Field{Type: t}
+		}
+		return fields
+	}
+
+	weatherType := graphql.NewObject(graphql.ObjectConfig{Name: "Weather", Fields: scalars(map[string]graphql.Output{
+		"temperature": graphql.Float, "tempMin": graphql.Float, "tempMax": graphql.Float,
+		"humidity": graphql.Float, "windSpeed": graphql.Float, "pressure": graphql.Float,
+	})})
+	thermalType := graphql.NewObject(graphql.ObjectConfig{Name: "Thermal", Fields: scalars(map[string]graphql.Output{
+		"feelsLike": graphql.Float, "heatIndex": graphql.Float, "windChill": graphql.Float,
+		"humidex": graphql.Float, "utci": graphql.Float,
+	})})
+	hourType := graphql.NewObject(graphql.ObjectConfig{Name: "Hour", Fields: scalars(map[string]graphql.Output{
+		"time": graphql.String, "temperature": graphql.Float, "feelsLike": graphql.Float,
+		"humidity": graphql.Float, "precipProb": graphql.Float, "windSpeed": graphql.Float,
+		"uvIndex": graphql.Float, "conditions": graphql.String, "comfortScore": graphql.Float,
+	})})
+	comfortType := graphql.NewObject(graphql.ObjectConfig{Name: "Comfort", Fields: scalars(map[string]graphql.Output{
+		"index": graphql.Float, "bestHours": graphql.NewList(graphql.String),
+	})})
+	quakeType := graphql.NewObject(graphql.ObjectConfig{Name: "Earthquake", Fields: scalars(map[string]graphql.Output{
+		"time": graphql.DateTime, "magnitude": graphql.Float, "place": graphql.String,
+	})})
+	quakesType := graphql.NewObject(graphql.ObjectConfig{Name: "Earthquakes", Fields: scalars(map[string]graphql.Output{
+		"risk": graphql.Float, "count": graphql.Int, "maxMag": graphql.Float, "maxMag7d": graphql.Float,
+		"recent": graphql.NewList(quakeType),
+	})})
+	countryType := graphql.NewObject(graphql.ObjectConfig{Name: "Country", Fields: scalars(map[string]graphql.Output{
+		"name": graphql.String, "gdpUsd": graphql.Float, "population": graphql.Int, "populationDensity": graphql.Float,
+	})})
+	cityStatsType := graphql.NewObject(graphql.ObjectConfig{Name: "CityStats", Fields: scalars(map[string]graphql.Output{
+		"population": graphql.Int, "densityPerKm2": graphql.Float,
+	})})
+
+	cityType := graphql.NewObject(graphql.ObjectConfig{Name: "City", Fields: graphql.Fields{
+		"name": &graphql.Field{Type: graphql.String, Description: "The requested city name",
+			Resolve: cityField(func(_ context.Context, r *cityResolver) (interface{}, error) { return r.name, nil })},
+		"resolvedAddress": &graphql.Field{Type: graphql.String,
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return w.City })},
+		"date": &graphql.Field{Type: graphql.String,
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return w.Date })},
+		"conditions": &graphql.Field{Type: graphql.String,
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return w.Conditions })},
+		"weather": &graphql.Field{Type: weatherType,
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return weatherV2(w).Weather })},
+		"thermal": &graphql.Field{Type: thermalType,
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return weatherV2(w).Thermal })},
+		"hours": &graphql.Field{Type: graphql.NewList(hourType),
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return weatherV2(w).Hours })},
+		"comfort": &graphql.Field{Type: comfortType,
+			Resolve: weatherField(func(w *WeatherResponse) interface{} { return weatherV2(w).Comfort })},
+
+		"airPurity": &graphql.Field{Type: graphql.Int,
+			Resolve: cityField(func(_ context.Context, r *cityResolver) (interface{}, error) { return getAirScore(r.name), nil })},
+		"roadTraffic": &graphql.Field{Type: graphql.Int,
+			Resolve: cityField(func(_ context.Context, r *cityResolver) (interface{}, error) { return getTrafficScore(r.name), nil })},
+		"crimeRisks": &graphql.Field{Type: graphql.Int,
+			Resolve: cityField(func(_ context.Context, r *cityResolver) (interface{}, error) { return getCrimeScore(r.name), nil })},
+
+		"earthquakes": &graphql.Field{Type: quakesType,
+			Resolve: cityField(func(ctx context.Context, r *cityResolver) (interface{}, error) {
+				if _, err := r.loadWeather(ctx); err != nil {
+					return nil, err
+				}
+				eq, err := r.s.earthquakes(ctx, r.body)
+				if eq == nil {
+					return nil, err
+				}
+				return eq, nil
+			})},
+		"country": &graphql.Field{Type: countryType,
+			Resolve: cityField(func(ctx context.Context, r *cityResolver) (interface{}, error) {
+				country, err := r.loadCountry(ctx)
+				if err != nil || country == "" {
+					return nil, err
+				}
+				gdp, pop, dens, err := r.s.fetchCountryStats(ctx, country)
+				if err != nil {
+					return nil, err
+				}
+				return &graphQLCountry{Name: country, GDPUSD: gdp, Population: pop, PopulationDensity: dens}, nil
+			})},
+		"cityStats": &graphql.Field{Type: cityStatsType,
+			Resolve: cityField(func(ctx context.Context, r *cityResolver) (interface{}, error) {
+				country, err := r.loadCountry(ctx)
+				if err != nil || country == "" {
+					return nil, err
+				}
+				pop, area, err := r.s.fetchCityStats(ctx, r.weather.City, country)
+				if err != nil {
+					return nil, err
+				}
+				out := &CityMetrics{Population: pop}
+				if area > 0 {
+					out.DensityPerKm2 = float64(pop) / area
+				}
+				return out, nil
+			})},
+		"aiForecast": &graphql.Field{Type: graphql.String,
+			Description: "One-line AI forecast; null for past days or when AI is not configured",
+			Resolve: cityField(func(ctx context.Context, r *cityResolver) (interface{}, error) {
+				return r.loadForecast(ctx)
+			})},
+	}})
+
+	query := graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+		"city": &graphql.Field{
+			Type: cityType,
+			Args: graphql.FieldConfigArgument{
+				"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"date": &graphql.ArgumentConfig{Type: graphql.String, Description: "DD-MM-YYYY or YYYY-MM-DD; today when omitted"},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				root := p.Source.(map[string]interface{})
+				name, _ := p.Args["name"].(string)
+				date, _ := p.Args["date"].(string)
+				if name == "" {
+					return nil, graphQLError{apierror.New(apierror.CodeMissingParameter, "city name required")}
+				}
+				return &cityResolver{s: root["server"].(*Server), name: name, date: date}, nil
+			},
+		},
+	}})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+})
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/publicthrone547/towards_project/internal/fixtures"
+	"github.com/publicthrone547/towards_project/internal/health"
+)
+
+// HealthChecks returns the readiness checks for this server: the database is
+// critical, upstreams only degrade readiness since handlers tolerate their
+// partial failure.
+func (s *Server) HealthChecks() []health.Check {
+	var checks []health.Check
+	if s.db != nil {
+		checks = append(checks, health.DBCheck(s.db))
+	}
+	if s.cfg.Outbound.Mode == fixtures.Replay {
+		// Upstreams are served from disk; probing the real hosts would only
+		// report the machine offline.
+		return checks
+	}
+	// Probes bypass the shared client so they do not show up as upstream
+	// traffic in metrics.
+	probe := &http.Client{}
+	probed := map[string]bool{}
+	for _, u := range upstreamHosts {
+		host, root, ok := upstreamRoot(u.section(s.cfg).BaseURL)
+		if !ok || probed[host] {
+			continue
+		}
+		probed[host] = true
+		checks = append(checks, health.HTTPCheck(u.name, probe, root))
+	}
+	return checks
+}
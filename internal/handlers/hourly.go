@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+// parseVisualCrossingHours converts the raw "hours" array of a Visual Crossing
+// timeline day into our typed hourly model.
+func parseVisualCrossingHours(raw []interface{}) []models.HourlyWeather {
+	out := make([]models.HourlyWeather, 0, len(raw))
+	for _, item := range raw {
+		hm, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		h := models.HourlyWeather{
+			Temperature: floatField(hm, "temp"),
+			FeelsLike:   floatField(hm, "feelslike"),
+			Humidity:    floatField(hm, "humidity"),
+			PrecipProb:  floatField(hm, "precipprob"),
+			WindSpeed:   floatField(hm, "windspeed"),
+			UVIndex:     floatField(hm, "uvindex"),
+		}
+		if t, ok := hm["datetime"].(string); ok {
+			h.Time = t
+		}
+		if cnd, ok := hm["conditions"].(string); ok {
+			h.Conditions = cnd
+		}
+		if _, ok := hm["feelslike"]; !ok {
+			h.FeelsLike = h.Temperature
+		}
+		h.ComfortScore = hourlyComfortScore(h)
+		out = append(out, h)
+	}
+	return out
+}
+
+// hourlyComfortScore rates a single hour from 0 to 100. It starts from the
+// same temperature score as the daily index (using feels-like) and subtracts
+// penalties for likely rain, strong wind and high UV.
+func hourlyComfortScore(h models.HourlyWeather) float64 {
+	score := temperatureScore(h.FeelsLike)
+
+	score -= h.PrecipProb * 0.4
+	if h.WindSpeed > 20 {
+		score -= (h.WindSpeed - 20) * 1.5
+	}
+	if h.UVIndex > 6 {
+		score -= (h.UVIndex - 6) * 5
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
+
+// bestHours returns the times of the n most comfortable hours, ordered
+// chronologically.
+func bestHours(hours []models.HourlyWeather, n int) []string {
+	if len(hours) == 0 || n <= 0 {
+		return nil
+	}
+	idx := make([]int, len(hours))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return hours[idx[a]].ComfortScore > hours[idx[b]].ComfortScore
+	})
+	if len(idx) > n {
+		idx = idx[:n]
+	}
+	sort.Ints(idx)
+
+	out := make([]string, 0, len(idx))
+	for _, i := range idx {
+		out = append(out, hours[i].Time)
+	}
+	return out
+}
+
+// temperatureScore maps a temperature to 0..100, losing 5 points per degree
+// away from 21°C.
+func temperatureScore(t float64) float64 {
+	diff := t - 21.0
+	if diff < 0 {
+		diff = -diff
+	}
+	score := 100.0 - diff*5.0
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
+
+func floatField(m map[string]interface{}, key string) float64 {
+	switch v := m[key].(type) {
+	case float64:
+		return v
+	case json.Number:
+		if fv, err := v.Float64(); err == nil {
+			return fv
+		}
+	}
+	return 0
+}
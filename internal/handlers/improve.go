@@ -1,12 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/publicthrone547/towards_project/internal/ai"
-	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/apierror"
 )
 
 type ImproveRequest struct {
@@ -19,22 +19,29 @@ type ImproveResponse struct {
 	Suggestions string `json:"suggestions"`
 }
 
-func ImproveHandler(c *gin.Context) {
+func (s *Server) ImproveHandler(c *gin.Context) {
 	var req ImproveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "city required"})
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInvalidRequest, err, "city required"))
 		return
 	}
 
-	cfg := config.Load()
-	key := cfg.GeminiAPIKey
+	reply, err := s.Improve(c.Request.Context(), req.City, req.Date, req.WeatherJSON)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ImproveResponse{Suggestions: reply})
+}
 
-	prompt := fmt.Sprintf("решение: Короткий ответ\nНе больше 50 слов. Provide practical, non-political, community-driven suggestions to improve the city '%s' (date=%s). Use the following metrics and propose infrastructure, environment, safety and public service improvements.\nMetrics:\n%v\n\nRespond concisely.", req.City, req.Date, req.WeatherJSON)
+// Improve asks for short improvement suggestions for city based on metrics,
+// truncated to 50 words.
+func (s *Server) Improve(ctx context.Context, city, date string, metrics map[string]interface{}) (string, error) {
+	prompt := fmt.Sprintf("решение: Короткий ответ\nНе больше 50 слов. Provide practical, non-political, community-driven suggestions to improve the city '%s' (date=%s). Use the following metrics and propose infrastructure, environment, safety and public service improvements.\nMetrics:\n%v\n\nRespond concisely.", city, date, metrics)
 
-	reply, err := ai.AskGemini(key, "", prompt)
+	reply, err := s.ai.Ask(ctx, "", prompt)
 	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "ai failed", "detail": err.Error()})
-		return
+		return "", aiUpstreamError(err)
 	}
 
 	words := splitWords(reply)
@@ -42,8 +49,7 @@ func ImproveHandler(c *gin.Context) {
 		words = words[:50]
 		reply = joinWords(words)
 	}
-
-	c.JSON(http.StatusOK, ImproveResponse{Suggestions: reply})
+	return reply, nil
 }
 
 func splitWords(s string) []string {
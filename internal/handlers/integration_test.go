@@ -0,0 +1,805 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/cache"
+	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+)
+
+func TestGetWeatherEnrichesPastDay(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodGet, "/weather?city=Paris&date=10-01-2026", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+
+	want := map[string]interface{}{
+		"city":             "Paris",
+		"date":             "10-01-2026",
+		"temperature":      21.0,
+		"conditions":       "Clear",
+		"gdp_usd":          3030000000000.0,
+		"population_total": 68000000.0,
+		"city_population":  2102650.0,
+		"earthquake_count": 2.0,
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+	if _, ok := body["ai_forecast"]; ok {
+		t.Errorf("past day got an AI forecast: %v", body["ai_forecast"])
+	}
+	if len(f.gemini.calls()) != 0 {
+		t.Errorf("gemini called %d times for a past day", len(f.gemini.calls()))
+	}
+
+	calls := f.weather.calls()
+	if len(calls) != 1 {
+		t.Fatalf("weather calls = %d, want 1", len(calls))
+	}
+	if got := calls[0].URL.Path; got != "/vc/timeline/Paris/2026-01-10" {
+		t.Errorf("weather path = %q", got)
+	}
+	if got := calls[0].URL.Query().Get("key"); got != "vc-test-key" {
+		t.Errorf("weather key = %q", got)
+	}
+	if got := f.nominatim.calls()[0].Header.Get("User-Agent"); got == "" {
+		t.Error("nominatim request without User-Agent")
+	}
+	usgs := f.usgs.calls()[0].URL
+	if usgs.Path != "/fdsnws/event/1/query.geojson" || usgs.Query().Get("latitude") != "48.856700" {
+		t.Errorf("usgs request = %s", usgs)
+	}
+	if got := f.countries.calls()[0].URL.Path; got != "/v3.1/name/France" {
+		t.Errorf("restcountries path = %q", got)
+	}
+	if got := f.worldBank.calls()[0].URL.Path; got != "/v2/country/fra/indicator/NY.GDP.MKTP.CD" {
+		t.Errorf("world bank path = %q", got)
+	}
+	if got := f.nominatim.calls()[0].URL.Path; got != "/search" {
+		t.Errorf("nominatim path = %q", got)
+	}
+}
+
+func TestGetWeatherTodayIncludesForecast(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodGet, "/weather?city=Paris", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	if body["ai_forecast"] != "Sunny and mild, take sunglasses." {
+		t.Errorf("ai_forecast = %v", body["ai_forecast"])
+	}
+	if body["date"] != time.Now().Format("02-01-2006") {
+		t.Errorf("date = %v", body["date"])
+	}
+
+	calls := f.gemini.calls()
+	if len(calls) != 1 {
+		t.Fatalf("gemini calls = %d, want 1", len(calls))
+	}
+	if got := calls[0].Header.Get("X-Goog-Api-Key"); got != "gemini-test-key" {
+		t.Errorf("gemini key header = %q", got)
+	}
+	if !strings.Contains(calls[0].Body, "City: Paris, Île-de-France, France") {
+		t.Errorf("forecast prompt does not name the city: %s", calls[0].Body)
+	}
+}
+
+// Without a date the current conditions are used, and the hours, which only
+// come with the days, still fill hours and best_hours.
+func TestGetWeatherTodayHours(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodGet, "/weather?city=Paris", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	if body["conditions"] != "Sunny" {
+		t.Errorf("conditions = %v, want the current conditions", body["conditions"])
+	}
+	hours, _ := body["hours"].([]interface{})
+	if len(hours) != 2 {
+		t.Fatalf("hours = %v, want the 2 hours of the first day", body["hours"])
+	}
+	if best, _ := body["best_hours"].([]interface{}); len(best) == 0 {
+		t.Errorf("best_hours = %v", body["best_hours"])
+	}
+
+	include := f.weather.calls()[0].URL.Query().Get("include")
+	for _, section := range []string{"current", "days", "hours"} {
+		if !strings.Contains(include, section) {
+			t.Errorf("include = %q, missing %s", include, section)
+		}
+	}
+}
+
+// Enrichments and the forecast are best effort: their failures leave fields
+// empty but the response succeeds.
+func TestGetWeatherPartialFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		break_  func(f *fakes)
+		missing []string
+		present []string
+	}{
+		{
+			name:    "countries down",
+			break_:  func(f *fakes) { f.countries.set(jsonHandler(http.StatusInternalServerError, `{}`)) },
+			missing: []string{"gdp_usd", "population_total"},
+			present: []string{"city_population", "earthquake_count"},
+		},
+		{
+			name:    "world bank down",
+			break_:  func(f *fakes) { f.worldBank.set(jsonHandler(http.StatusServiceUnavailable, `{}`)) },
+			missing: []string{"gdp_usd"},
+			present: []string{"population_total"},
+		},
+		{
+			name:    "nominatim malformed",
+			break_:  func(f *fakes) { f.nominatim.set(jsonHandler(http.StatusOK, `not json`)) },
+			missing: []string{"city_population"},
+			present: []string{"gdp_usd", "earthquake_count"},
+		},
+		{
+			name:    "usgs down",
+			break_:  func(f *fakes) { f.usgs.set(jsonHandler(http.StatusBadGateway, `{}`)) },
+			missing: []string{"earthquake_count", "earthquake_risk"},
+			present: []string{"gdp_usd", "city_population"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakes(t)
+			tt.break_(f)
+			r := newRouter(newTestServer(t, f, testConfig(f)))
+
+			status, body := do(t, r, http.MethodGet, "/weather?city=Paris&date=2026-01-10", nil)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d, body = %v", status, body)
+			}
+			if body["temperature"] != 21.0 {
+				t.Errorf("temperature = %v", body["temperature"])
+			}
+			for _, k := range tt.missing {
+				if v, ok := body[k]; ok {
+					t.Errorf("%s = %v, want it omitted", k, v)
+				}
+			}
+			for _, k := range tt.present {
+				if _, ok := body[k]; !ok {
+					t.Errorf("%s missing", k)
+				}
+			}
+		})
+	}
+}
+
+// A World Bank failure must not be cached with the rest of the country
+// stats: the next request retries it.
+func TestGetWeatherGDPNotCachedOnFailure(t *testing.T) {
+	f := newFakes(t)
+	f.worldBank.set(jsonHandler(http.StatusServiceUnavailable, `{}`))
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	_, body := do(t, r, http.MethodGet, "/weather?city=Paris&date=2026-01-10", nil)
+	if _, ok := body["gdp_usd"]; ok {
+		t.Fatalf("gdp_usd = %v with the World Bank down", body["gdp_usd"])
+	}
+
+	f.worldBank.set(worldBankHandler)
+	_, body = do(t, r, http.MethodGet, "/weather?city=Paris&date=2026-01-11", nil)
+	if body["gdp_usd"] != 3030000000000.0 {
+		t.Errorf("gdp_usd = %v after the World Bank recovered", body["gdp_usd"])
+	}
+	if n := len(f.countries.calls()); n != 2 {
+		t.Errorf("restcountries calls = %d, want 2", n)
+	}
+
+	// Once complete, the stats are cached.
+	do(t, r, http.MethodGet, "/weather?city=Paris&date=2026-01-12", nil)
+	if n := len(f.worldBank.calls()); n != 2 {
+		t.Errorf("world bank calls = %d, want 2", n)
+	}
+}
+
+func TestGetWeatherForecastFailure(t *testing.T) {
+	f := newFakes(t)
+	f.gemini.set(jsonHandler(http.StatusInternalServerError, `{"error":{"code":500,"message":"backend error","status":"INTERNAL"}}`))
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodGet, "/weather?city=Paris", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	forecast, _ := body["ai_forecast"].(string)
+	if !strings.HasPrefix(forecast, "gemini error: ") || !strings.Contains(forecast, "backend error") {
+		t.Errorf("ai_forecast = %q", forecast)
+	}
+	if body["conditions"] != "Sunny" {
+		t.Errorf("conditions = %v", body["conditions"])
+	}
+}
+
+func TestGetWeatherErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		upstream func(f *fakes)
+		status   int
+		code     string
+		noCalls  bool
+	}{
+		{name: "missing city", query: "", status: http.StatusBadRequest, code: "MISSING_PARAMETER", noCalls: true},
+		{name: "invalid date", query: "city=Paris&date=2026/01/10", status: http.StatusBadRequest, code: "INVALID_DATE", noCalls: true},
+		{
+			name:  "unknown city",
+			query: "city=Atlantis&date=2026-01-10",
+			upstream: func(f *fakes) {
+				f.weather.set(jsonHandler(http.StatusBadRequest, `Bad API Request:Invalid location parameter value.`))
+			},
+			status: http.StatusNotFound,
+			code:   "CITY_NOT_FOUND",
+		},
+		{
+			name:     "provider down",
+			query:    "city=Paris&date=2026-01-10",
+			upstream: func(f *fakes) { f.weather.set(jsonHandler(http.StatusServiceUnavailable, `{}`)) },
+			status:   http.StatusBadGateway,
+			code:     "UPSTREAM_WEATHER_UNAVAILABLE",
+		},
+		{
+			name:     "malformed response",
+			query:    "city=Paris&date=2026-01-10",
+			upstream: func(f *fakes) { f.weather.set(jsonHandler(http.StatusOK, `<html>`)) },
+			status:   http.StatusBadGateway,
+			code:     "UPSTREAM_WEATHER_INVALID_RESPONSE",
+		},
+		{
+			name:     "no day data",
+			query:    "city=Paris&date=2026-01-10",
+			upstream: func(f *fakes) { f.weather.set(jsonHandler(http.StatusOK, `{"days":[]}`)) },
+			status:   http.StatusBadGateway,
+			code:     "UPSTREAM_WEATHER_INVALID_RESPONSE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakes(t)
+			if tt.upstream != nil {
+				tt.upstream(f)
+			}
+			r := newRouter(newTestServer(t, f, testConfig(f)))
+
+			status, body := do(t, r, http.MethodGet, "/weather?"+tt.query, nil)
+			if status != tt.status || body["code"] != tt.code {
+				t.Fatalf("got %d %v, want %d %s; body = %v", status, body["code"], tt.status, tt.code, body)
+			}
+			if detail, _ := body["detail"].(string); strings.Contains(detail, "vc-test-key") {
+				t.Errorf("detail leaks the API key: %q", detail)
+			}
+			if tt.noCalls && len(f.weather.calls()) != 0 {
+				t.Errorf("weather provider called %d times", len(f.weather.calls()))
+			}
+			for _, u := range []*fakeUpstream{f.usgs, f.countries, f.worldBank, f.nominatim, f.gemini} {
+				if n := len(u.calls()); n != 0 {
+					t.Errorf("enrichment upstream %s called %d times after a failed lookup", u.URL, n)
+				}
+			}
+		})
+	}
+}
+
+func TestAskHandler(t *testing.T) {
+	f := newFakes(t)
+	f.gemini.set(geminiHandler("Plant more trees along the river."))
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodPost, "/ask", map[string]string{"prompt": "How can Paris get greener?"})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	if body["reply"] != "Plant more trees along the river." {
+		t.Errorf("reply = %v", body["reply"])
+	}
+
+	calls := f.gemini.calls()
+	if len(calls) != 1 {
+		t.Fatalf("gemini calls = %d, want 1", len(calls))
+	}
+	if got := calls[0].URL.Path; got != "/v1beta/models/gemini-2.0-flash:generateContent" {
+		t.Errorf("gemini path = %q", got)
+	}
+	var req struct {
+		Contents []struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	if err := json.Unmarshal([]byte(calls[0].Body), &req); err != nil {
+		t.Fatalf("decode gemini request: %v", err)
+	}
+	if len(req.Contents) != 1 || len(req.Contents[0].Parts) != 1 {
+		t.Fatalf("gemini request = %s", calls[0].Body)
+	}
+	text := req.Contents[0].Parts[0].Text
+	if !strings.Contains(text, "city improvement chat") || !strings.HasSuffix(text, "How can Paris get greener?") {
+		t.Errorf("prompt = %q", text)
+	}
+}
+
+func TestAskHandlerCustomInstruction(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, _ := do(t, r, http.MethodPost, "/ask", map[string]string{"prompt": "hi", "instruction": "Answer like a pirate."})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	body := f.gemini.calls()[0].Body
+	if !strings.Contains(body, "Answer like a pirate.") || strings.Contains(body, "city improvement chat") {
+		t.Errorf("gemini request = %s", body)
+	}
+}
+
+// aiFailures are Gemini responses mapped to distinct API errors, shared by
+// the /ask and /improve tests.
+var aiFailures = []struct {
+	name    string
+	handler http.HandlerFunc
+	status  int
+	code    string
+}{
+	{
+		name:    "rate limited",
+		handler: jsonHandler(http.StatusTooManyRequests, `{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`),
+		status:  http.StatusServiceUnavailable,
+		code:    "UPSTREAM_AI_RATE_LIMITED",
+	},
+	{
+		name:    "server error",
+		handler: jsonHandler(http.StatusInternalServerError, `{"error":{"code":500,"message":"internal","status":"INTERNAL"}}`),
+		status:  http.StatusBadGateway,
+		code:    "UPSTREAM_AI_UNAVAILABLE",
+	},
+	{
+		name:    "prompt blocked",
+		handler: jsonHandler(http.StatusOK, `{"promptFeedback":{"blockReason":"SAFETY"}}`),
+		status:  http.StatusUnprocessableEntity,
+		code:    "AI_CONTENT_BLOCKED",
+	},
+	{
+		name:    "no candidates",
+		handler: jsonHandler(http.StatusOK, `{"candidates":[]}`),
+		status:  http.StatusBadGateway,
+		code:    "AI_EMPTY_RESPONSE",
+	},
+}
+
+func TestAskHandlerErrors(t *testing.T) {
+	t.Run("missing prompt", func(t *testing.T) {
+		f := newFakes(t)
+		r := newRouter(newTestServer(t, f, testConfig(f)))
+		status, body := do(t, r, http.MethodPost, "/ask", map[string]string{"instruction": "x"})
+		if status != http.StatusBadRequest || body["code"] != "INVALID_REQUEST" {
+			t.Errorf("got %d %v", status, body)
+		}
+		if len(f.gemini.calls()) != 0 {
+			t.Error("gemini called for an invalid request")
+		}
+	})
+	for _, tt := range aiFailures {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakes(t)
+			f.gemini.set(tt.handler)
+			r := newRouter(newTestServer(t, f, testConfig(f)))
+
+			status, body := do(t, r, http.MethodPost, "/ask", map[string]string{"prompt": "hi"})
+			if status != tt.status || body["code"] != tt.code {
+				t.Errorf("got %d %v, want %d %s; body = %v", status, body["code"], tt.status, tt.code, body)
+			}
+		})
+	}
+}
+
+func TestImproveHandler(t *testing.T) {
+	f := newFakes(t)
+	long := strings.Repeat("word ", 80)
+	f.gemini.set(geminiHandler(long))
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodPost, "/improve", map[string]interface{}{
+		"city":    "Paris",
+		"date":    "10-01-2026",
+		"weather": map[string]interface{}{"air_purity": 42},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	suggestions, _ := body["suggestions"].(string)
+	if n := len(strings.Fields(suggestions)); n != 50 {
+		t.Errorf("suggestions has %d words, want 50", n)
+	}
+
+	prompt := f.gemini.calls()[0].Body
+	for _, want := range []string{"'Paris'", "date=10-01-2026", "air_purity:42"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q: %s", want, prompt)
+		}
+	}
+}
+
+func TestImproveHandlerErrors(t *testing.T) {
+	t.Run("missing city", func(t *testing.T) {
+		f := newFakes(t)
+		r := newRouter(newTestServer(t, f, testConfig(f)))
+		status, body := do(t, r, http.MethodPost, "/improve", map[string]string{"date": "10-01-2026"})
+		if status != http.StatusBadRequest || body["code"] != "INVALID_REQUEST" {
+			t.Errorf("got %d %v", status, body)
+		}
+	})
+	for _, tt := range aiFailures {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakes(t)
+			f.gemini.set(tt.handler)
+			r := newRouter(newTestServer(t, f, testConfig(f)))
+
+			status, body := do(t, r, http.MethodPost, "/improve", map[string]string{"city": "Paris"})
+			if status != tt.status || body["code"] != tt.code {
+				t.Errorf("got %d %v, want %d %s; body = %v", status, body["code"], tt.status, tt.code, body)
+			}
+		})
+	}
+}
+
+func TestUpstreamHeaders(t *testing.T) {
+	f := newFakes(t)
+	cfg := testConfig(f)
+	cfg.AI.Headers = map[string]string{"Authorization": "Bearer gateway-token"}
+	cfg.Geocoding.Headers = map[string]string{"X-Tenant": "towards", "User-Agent": "override/1.0"}
+	r := newRouter(newTestServer(t, f, cfg))
+
+	if status, body := do(t, r, http.MethodGet, "/weather?city=Paris", nil); status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+
+	gemini := f.gemini.calls()[0].Header
+	if got := gemini.Get("Authorization"); got != "Bearer gateway-token" {
+		t.Errorf("gemini Authorization = %q", got)
+	}
+	if got := gemini.Get("X-Goog-Api-Key"); got != "gemini-test-key" {
+		t.Errorf("gemini key header = %q", got)
+	}
+	nominatim := f.nominatim.calls()[0].Header
+	if nominatim.Get("X-Tenant") != "towards" || nominatim.Get("User-Agent") != "override/1.0" {
+		t.Errorf("nominatim headers = %v", nominatim)
+	}
+	// Headers stay with their upstream.
+	for _, u := range []*fakeUpstream{f.weather, f.usgs, f.countries, f.worldBank} {
+		h := u.calls()[0].Header
+		if h.Get("Authorization") != "" || h.Get("X-Tenant") != "" {
+			t.Errorf("%s got another upstream's headers: %v", u.URL, h)
+		}
+	}
+}
+
+// TestUpstreamBaseURLs checks the harness wiring the other tests rely on:
+// every upstream reaches its fake only through its configured base URL, and
+// nothing rewrites requests for the default hosts.
+func TestUpstreamBaseURLs(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	if status, body := do(t, r, http.MethodGet, "/weather?city=Paris", nil); status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	prefixes := []struct {
+		name   string
+		fake   *fakeUpstream
+		prefix string
+	}{
+		{"visualcrossing", f.weather, "/vc/timeline/"},
+		{"usgs", f.usgs, "/fdsnws/event/1/"},
+		{"restcountries", f.countries, "/v3.1/"},
+		{"worldbank", f.worldBank, "/v2/"},
+		{"nominatim", f.nominatim, "/search"},
+		{"gemini", f.gemini, "/v1beta/models/"},
+	}
+	for _, p := range prefixes {
+		calls := p.fake.calls()
+		if len(calls) == 0 {
+			t.Errorf("%s: no requests", p.name)
+		}
+		for _, c := range calls {
+			if !strings.HasPrefix(c.URL.Path, p.prefix) {
+				t.Errorf("%s: request %s outside base URL path %s", p.name, c.URL, p.prefix)
+			}
+		}
+	}
+
+	// With the default base URLs the same server has no way to the fakes.
+	cfg := testConfig(f)
+	def := config.Default()
+	cfg.Weather.BaseURL = def.Weather.BaseURL
+	cfg.USGS.BaseURL = def.USGS.BaseURL
+	cfg.Countries.BaseURL = def.Countries.BaseURL
+	cfg.WorldBank.BaseURL = def.WorldBank.BaseURL
+	cfg.Geocoding.BaseURL = def.Geocoding.BaseURL
+	cfg.AI.BaseURL = def.AI.BaseURL
+	before := 0
+	for _, u := range f.all() {
+		before += len(u.calls())
+	}
+	r = newRouter(newTestServer(t, f, cfg))
+	if status, body := do(t, r, http.MethodGet, "/weather?city=Paris&date=10-01-2026", nil); status == http.StatusOK {
+		t.Errorf("default base URLs: status 200, body = %v", body)
+	}
+	after := 0
+	for _, u := range f.all() {
+		after += len(u.calls())
+	}
+	if after != before {
+		t.Errorf("default base URLs reached the fakes: %d requests", after-before)
+	}
+}
+
+func TestGetCountry(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	status, body := do(t, r, http.MethodGet, "/countries/fra", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	for k, v := range map[string]interface{}{
+		"code":         "FRA",
+		"iso2":         "FR",
+		"name":         "France",
+		"region":       "Europe & Central Asia",
+		"income_level": "High income",
+		"capital":      "Paris",
+	} {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+
+	// Null latest years are skipped for the newest published value.
+	tests := []struct {
+		field string
+		value float64
+		year  float64
+	}{
+		{"gdp_per_capita", 44690.9, 2024},
+		{"life_expectancy", 83.3, 2023},
+		{"unemployment", 7.4, 2024},
+		{"co2_per_capita", 4.4, 2022},
+	}
+	for _, tt := range tests {
+		v, ok := body[tt.field].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s missing", tt.field)
+			continue
+		}
+		if v["value"] != tt.value || v["year"] != tt.year {
+			t.Errorf("%s = %v (%v), want %v (%v)", tt.field, v["value"], v["year"], tt.value, tt.year)
+		}
+	}
+	if v, ok := body["urban_population"]; ok {
+		t.Errorf("urban_population = %v, want omitted when every year is null", v)
+	}
+
+	calls := f.worldBank.calls()
+	if len(calls) != 2 {
+		t.Fatalf("world bank calls = %d, want 2", len(calls))
+	}
+	if got := calls[0].URL.Path; got != "/v2/country/FRA" {
+		t.Errorf("metadata path = %q", got)
+	}
+	q := calls[1].URL.Query()
+	if !strings.HasPrefix(calls[1].URL.Path, "/v2/country/FRA/indicator/NY.GDP.PCAP.CD;") || q.Get("source") != "2" || q.Get("date") == "" {
+		t.Errorf("indicators request = %s", calls[1].URL)
+	}
+
+	// Profiles are cached per code.
+	if status, _ := do(t, r, http.MethodGet, "/countries/FRA", nil); status != http.StatusOK {
+		t.Errorf("cached status = %d", status)
+	}
+	if n := len(f.worldBank.calls()); n != 2 {
+		t.Errorf("world bank calls after cached request = %d, want 2", n)
+	}
+}
+
+func TestGetCountryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		break_ func(f *fakes)
+		status int
+		want   string
+	}{
+		{name: "bad code", code: "F1", status: http.StatusBadRequest, want: "INVALID_REQUEST"},
+		{name: "too long", code: "FRAN", status: http.StatusBadRequest, want: "INVALID_REQUEST"},
+		{name: "unknown", code: "XK", status: http.StatusNotFound, want: "COUNTRY_NOT_FOUND"},
+		{
+			name:   "world bank down",
+			code:   "FR",
+			break_: func(f *fakes) { f.worldBank.set(jsonHandler(http.StatusServiceUnavailable, `{}`)) },
+			status: http.StatusBadGateway,
+			want:   "UPSTREAM_COUNTRY_UNAVAILABLE",
+		},
+		{
+			name:   "world bank malformed",
+			code:   "FR",
+			break_: func(f *fakes) { f.worldBank.set(jsonHandler(http.StatusOK, `not json`)) },
+			status: http.StatusBadGateway,
+			want:   "UPSTREAM_COUNTRY_UNAVAILABLE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakes(t)
+			if tt.break_ != nil {
+				tt.break_(f)
+			}
+			r := newRouter(newTestServer(t, f, testConfig(f)))
+
+			status, body := do(t, r, http.MethodGet, "/countries/"+tt.code, nil)
+			if status != tt.status || body["code"] != tt.want {
+				t.Errorf("got %d %v, want %d %s; body = %v", status, body["code"], tt.status, tt.want, body)
+			}
+		})
+	}
+}
+
+func TestGraphQLCityLimit(t *testing.T) {
+	cities := func(n int, field string) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "c%d: city(name: \"Paris\") { %s } ", i, field)
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"at limit", "{ " + cities(10, "airPurity") + "}", http.StatusOK},
+		{"aliases", "{ " + cities(11, "airPurity") + "}", http.StatusBadRequest},
+		{"fragments", "query { ...a ...a } fragment a on Query { " + cities(6, "airPurity") + "}", http.StatusBadRequest},
+		{"inline fragment", "{ ... on Query { " + cities(11, "airPurity") + "} }", http.StatusBadRequest},
+		{"other operation", "query Small { c: city(name: \"Paris\") { airPurity } } query Big { " + cities(11, "airPurity") + "}", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakes(t)
+			r := newRouter(newTestServer(t, f, testConfig(f)))
+
+			req := handlers.GraphQLRequest{Query: tt.query}
+			if tt.name == "other operation" {
+				req.OperationName = "Small"
+			}
+			status, body := do(t, r, http.MethodPost, "/graphql", req)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d; body = %v", status, tt.status, body)
+			}
+			if status == http.StatusBadRequest && body["code"] != "INVALID_REQUEST" {
+				t.Errorf("code = %v", body["code"])
+			}
+			for _, u := range f.all() {
+				if n := len(u.calls()); n != 0 {
+					t.Errorf("%s called %d times", u.URL, n)
+				}
+			}
+		})
+	}
+}
+
+// Aliases of aiForecast on one city share a single Gemini call.
+func TestGraphQLForecastOnce(t *testing.T) {
+	f := newFakes(t)
+	r := newRouter(newTestServer(t, f, testConfig(f)))
+
+	req := handlers.GraphQLRequest{Query: `{ city(name: "Paris") { a: aiForecast b: aiForecast } }`}
+	status, body := do(t, r, http.MethodPost, "/graphql", req)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d; body = %v", status, body)
+	}
+	city, _ := body["data"].(map[string]interface{})["city"].(map[string]interface{})
+	for _, alias := range []string{"a", "b"} {
+		if city[alias] != "Sunny and mild, take sunglasses." {
+			t.Errorf("%s = %v; body = %v", alias, city[alias], body)
+		}
+	}
+	if n := len(f.gemini.calls()); n != 1 {
+		t.Errorf("gemini calls = %d, want 1", n)
+	}
+}
+
+// TestReplayFixtures serves /weather from the committed fixture set with the
+// default upstream URLs and no network.
+func TestReplayFixtures(t *testing.T) {
+	cfg := config.Default()
+	cfg.Outbound.Mode = "replay"
+	cfg.Outbound.FixturesDir = "../../testdata/fixtures"
+	cfg.Weather.APIKey = "replay"
+	cfg.AI.APIKey = "replay"
+
+	client := &http.Client{Transport: httpclient.NewTransport(
+		handlers.UpstreamTransport(cfg, localOnly{}),
+		handlers.OutboundOptions(cfg),
+		handlers.Upstreams(cfg),
+	)}
+	s, err := handlers.NewServer(cfg, nil, client, cache.New(time.Minute), ai.NewClient(cfg.AI, client, nil))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	r := newRouter(s)
+
+	status, body := do(t, r, http.MethodGet, "/weather?city=Paris&date=15-06-2025", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	want := map[string]interface{}{
+		"city":             "Paris",
+		"date":             "15-06-2025",
+		"temperature":      27.4,
+		"conditions":       "Clear",
+		"gdp_usd":          3162079269265.25,
+		"population_total": 68373433.0,
+		"city_population":  2103778.0,
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+
+	// Without a fixture for the request, replay fails instead of guessing.
+	status, body = do(t, r, http.MethodGet, "/weather?city=Lyon&date=15-06-2025", nil)
+	if status == http.StatusOK {
+		t.Errorf("unrecorded city: status 200, body = %v", body)
+	}
+}
+
+func TestTracePropagation(t *testing.T) {
+	cfg := config.Default()
+	cfg.AI.BaseURL = "http://llm-gateway.internal/v1beta/"
+	cfg.AI.PropagateTrace = true
+
+	got := handlers.TracePropagation(cfg)
+	want := map[string]bool{
+		"weather.visualcrossing.com":  false,
+		"earthquake.usgs.gov":         false,
+		"restcountries.com":           false,
+		"api.worldbank.org":           false,
+		"nominatim.openstreetmap.org": false,
+		"llm-gateway.internal":        true,
+	}
+	for host, v := range want {
+		if p, ok := got[host]; !ok || p != v {
+			t.Errorf("%s = %v (present %v), want %v", host, p, ok, v)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("hosts = %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/alerts"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/cache"
+	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/countries"
+	"github.com/publicthrone547/towards_project/internal/subscriptions"
+	"github.com/publicthrone547/towards_project/internal/worker"
+)
+
+// Server owns everything the HTTP handlers depend on. Handlers are methods so
+// several independent instances can run side by side, e.g. in tests.
+type Server struct {
+	cfg    *config.Config
+	db     *sqlx.DB
+	client *http.Client
+	cache  *cache.Cache
+	ai     *ai.Client
+	alerts *alerts.Engine
+	// countries reads World Bank country profiles and indicators.
+	countries *countries.Client
+
+	subscriptions  *subscriptions.Store
+	snapshots      *worker.Store
+	snapshotMaxAge time.Duration
+	apiKeys        *auth.Store
+}
+
+// NewServer wires a Server. db may be nil, in which case the Postgres-backed
+// endpoints respond 503 and /weather always calls the upstreams.
+func NewServer(cfg *config.Config, db *sqlx.DB, client *http.Client, c *cache.Cache, aiClient *ai.Client) (*Server, error) {
+	s := &Server{
+		cfg:       cfg,
+		db:        db,
+		client:    client,
+		cache:     c,
+		ai:        aiClient,
+		alerts:    alerts.NewEngine(alerts.DefaultRules()),
+		countries: countries.NewClient(cfg.WorldBank.BaseURL, client),
+	}
+
+	if cfg.Alerts.RulesFile != "" {
+		rules, err := alerts.LoadRules(cfg.Alerts.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		s.alerts = alerts.NewEngine(rules)
+	}
+
+	if db != nil {
+		s.subscriptions = subscriptions.NewStore(db)
+		s.apiKeys = auth.NewStore(db)
+		if len(cfg.Worker.Cities) > 0 {
+			s.snapshots = worker.NewStore(db)
+			// Allow one missed run before falling back to live upstream calls.
+			s.snapshotMaxAge = 2 * cfg.Worker.Interval
+		}
+	}
+	return s, nil
+}
+
+func (s *Server) Config() *config.Config {
+	return s.cfg
+}
+
+func (s *Server) Subscriptions() *subscriptions.Store {
+	return s.subscriptions
+}
+
+func (s *Server) Snapshots() *worker.Store {
+	return s.snapshots
+}
+
+func (s *Server) APIKeys() *auth.Store {
+	return s.apiKeys
+}
+
+// getJSON performs a GET through the shared client, which applies the
+// upstream's timeout and retry policy, and decodes a 200 response into out.
+func (s *Server) getJSON(ctx context.Context, u string, header http.Header, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &decodeError{Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/models"
+	"github.com/publicthrone547/towards_project/internal/worker"
+)
+
+// CollectSnapshot is the worker.Collector used by the city refresher.
+func (s *Server) CollectSnapshot(ctx context.Context, city string) (*models.CitySnapshot, error) {
+	out, _, err := s.collectWeather(ctx, city, "")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &models.CitySnapshot{City: city, Payload: payload}
+	snap.Temperature = fmt.Sprintf("%.1f", out.Temperature)
+	snap.QualityIndex = fmt.Sprintf("%d", out.AirPurity)
+	snap.WorkloadPoints = fmt.Sprintf("%d", out.RoadTraffic)
+	snap.SafetyAssessment = fmt.Sprintf("%d", out.CrimeRisks)
+	snap.GeneralRatingOfComfort = fmt.Sprintf("%.1f", out.LifeComfortIdx)
+	return snap, nil
+}
+
+// loadWeather returns stored data for current-day requests when a fresh
+// snapshot (refreshed by the worker) exists, and falls back to collectWeather otherwise.
+func (s *Server) loadWeather(ctx context.Context, city, dateParam string) (*WeatherResponse, time.Time, error) {
+	if dateParam == "" && s.snapshots != nil {
+		snap, err := s.snapshots.Latest(ctx, city, s.snapshotMaxAge)
+		if err == nil {
+			var out WeatherResponse
+			if err := json.Unmarshal(snap.Payload, &out); err == nil {
+				day := snap.CollectedAt.UTC()
+				return &out, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC), nil
+			}
+			logging.FromContext(ctx).Warnf("decode snapshot %d: %v", snap.ID, err)
+		} else if err != worker.ErrNoSnapshot {
+			logging.FromContext(ctx).Warnf("load snapshot for %s: %v", city, err)
+		}
+	}
+	return s.collectWeather(ctx, city, dateParam)
+}
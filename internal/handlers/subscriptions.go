@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/alerts"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/models"
+	"github.com/publicthrone547/towards_project/internal/subscriptions"
+)
+
+type SubscriptionRequest struct {
+	City            string   `json:"city" binding:"required"`
+	WebhookURL      string   `json:"webhook_url" binding:"required"`
+	MinComfortIndex *float64 `json:"min_comfort_index,omitempty"`
+	MinSeverity     string   `json:"min_severity,omitempty"`
+}
+
+type SubscriptionResponse struct {
+	models.Subscription
+	// Secret is only returned when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (s *Server) CreateSubscription(c *gin.Context) {
+	if s.subscriptions == nil {
+		apierror.Respond(c, notConfigured("subscriptions"))
+		return
+	}
+
+	var req SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInvalidRequest, err, "city and webhook_url required"))
+		return
+	}
+
+	if err := subscriptions.CheckWebhookURL(c.Request.Context(), req.WebhookURL); err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInvalidRequest, err, "%s", err))
+		return
+	}
+	if req.MinComfortIndex != nil && (*req.MinComfortIndex < 0 || *req.MinComfortIndex > 100) {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "min_comfort_index must be between 0 and 100"))
+		return
+	}
+	switch alerts.Severity(req.MinSeverity) {
+	case "":
+		req.MinSeverity = string(alerts.SeveritySevere)
+	case alerts.SeverityInfo, alerts.SeverityWarning, alerts.SeveritySevere:
+	default:
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "min_severity must be info, warning or severe"))
+		return
+	}
+
+	secret, err := subscriptions.NewSecret()
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to generate secret"))
+		return
+	}
+
+	sub := models.Subscription{
+		APIKeyID:        keyID(c),
+		City:            req.City,
+		WebhookURL:      req.WebhookURL,
+		Secret:          secret,
+		MinComfortIndex: req.MinComfortIndex,
+		MinSeverity:     req.MinSeverity,
+	}
+	if err := s.subscriptions.Create(c.Request.Context(), &sub); err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to save subscription"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, SubscriptionResponse{Subscription: sub, Secret: secret})
+}
+
+func (s *Server) ListSubscriptions(c *gin.Context) {
+	if s.subscriptions == nil {
+		apierror.Respond(c, notConfigured("subscriptions"))
+		return
+	}
+
+	subs, err := s.subscriptions.List(c.Request.Context(), keyID(c))
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to list subscriptions"))
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+func (s *Server) DeleteSubscription(c *gin.Context) {
+	if s.subscriptions == nil {
+		apierror.Respond(c, notConfigured("subscriptions"))
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Respond(c, apierror.New(apierror.CodeInvalidRequest, "invalid subscription id"))
+		return
+	}
+
+	err = s.subscriptions.Delete(c.Request.Context(), id, keyID(c))
+	if errors.Is(err, subscriptions.ErrNotFound) {
+		apierror.Respond(c, apierror.New(apierror.CodeNotFound, "subscription not found"))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, apierror.Wrap(apierror.CodeInternal, err, "failed to delete subscription"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// keyID returns the ID of the API key that authenticated the request, which
+// owns the subscriptions it creates, or nil when auth is disabled.
+func keyID(c *gin.Context) *int64 {
+	if k := auth.KeyFromContext(c); k != nil {
+		return &k.ID
+	}
+	return nil
+}
+
+// EvaluateCity is the subscriptions.Evaluator used by the scheduler.
+func (s *Server) EvaluateCity(ctx context.Context, city string) (float64, []alerts.Alert, error) {
+	out, _, err := s.collectWeather(ctx, city, "")
+	if err != nil {
+		return 0, nil, err
+	}
+	return out.LifeComfortIdx, out.Alerts, nil
+}
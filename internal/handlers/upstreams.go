@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/publicthrone547/towards_project/internal/config"
+	"github.com/publicthrone547/towards_project/internal/fixtures"
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+)
+
+// upstreamHosts lists every external service with the config section that
+// governs it. Policies, metrics and probes are keyed by the host of the
+// section's base URL; when several upstreams share a host (one gateway in
+// front of them all) the first listed wins.
+var upstreamHosts = []struct {
+	name    string
+	section func(*config.Config) config.UpstreamConfig
+}{
+	{"visualcrossing", func(c *config.Config) config.UpstreamConfig { return c.Weather }},
+	{"usgs", func(c *config.Config) config.UpstreamConfig { return c.USGS }},
+	{"restcountries", func(c *config.Config) config.UpstreamConfig { return c.Countries }},
+	{"worldbank", func(c *config.Config) config.UpstreamConfig { return c.WorldBank }},
+	{"nominatim", func(c *config.Config) config.UpstreamConfig { return c.Geocoding }},
+	{"gemini", func(c *config.Config) config.UpstreamConfig {
+		return config.UpstreamConfig{APIKey: c.AI.APIKey, BaseURL: c.AI.BaseURL, Headers: c.AI.Headers, Timeout: c.AI.Timeout, PropagateTrace: c.AI.PropagateTrace}
+	}},
+}
+
+// upstreamRoot returns the host of base and its root URL, which /readyz
+// probes: only reachability is checked, so no API quota is spent.
+func upstreamRoot(base string) (host, root string, ok bool) {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	return u.Host, u.Scheme + "://" + u.Host + "/", true
+}
+
+// endpoint resolves path against an upstream base URL.
+func endpoint(base, path string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// UpstreamProviders maps upstream hosts to the provider label used in
+// metrics and traces.
+func UpstreamProviders(cfg *config.Config) map[string]string {
+	providers := make(map[string]string, len(upstreamHosts))
+	for _, u := range upstreamHosts {
+		host, _, ok := upstreamRoot(u.section(cfg).BaseURL)
+		if _, taken := providers[host]; ok && !taken {
+			providers[host] = u.name
+		}
+	}
+	return providers
+}
+
+// TracePropagation returns the upstream hosts that receive the trace
+// context: those whose section sets PropagateTrace. A host shared by several
+// sections follows the first listed, as for the other policies.
+func TracePropagation(cfg *config.Config) map[string]bool {
+	propagate := make(map[string]bool, len(upstreamHosts))
+	for _, u := range upstreamHosts {
+		sec := u.section(cfg)
+		host, _, ok := upstreamRoot(sec.BaseURL)
+		if _, taken := propagate[host]; ok && !taken {
+			propagate[host] = sec.PropagateTrace
+		}
+	}
+	return propagate
+}
+
+// Upstreams returns the outbound client policy for every upstream host.
+func Upstreams(cfg *config.Config) map[string]httpclient.Upstream {
+	policies := make(map[string]httpclient.Upstream, len(upstreamHosts))
+	for _, u := range upstreamHosts {
+		sec := u.section(cfg)
+		host, _, ok := upstreamRoot(sec.BaseURL)
+		if _, taken := policies[host]; !ok || taken {
+			continue
+		}
+		if cfg.Outbound.Mode == fixtures.Replay {
+			// Fixtures have no rate limits to respect.
+			sec.MinInterval = 0
+		}
+		var header http.Header
+		if len(sec.Headers) > 0 {
+			header = make(http.Header, len(sec.Headers))
+			for name, value := range sec.Headers {
+				header.Set(name, value)
+			}
+		}
+		policies[host] = httpclient.Upstream{
+			Name:        u.name,
+			Timeout:     sec.Timeout,
+			MinInterval: sec.MinInterval,
+			// generateContent has no side effects, so retrying a 503 is safe.
+			RetryUnsafe: u.name == "gemini",
+			Header:      header,
+		}
+	}
+	return policies
+}
+
+// OutboundOptions returns the shared client's retry and breaker settings.
+// Replay disables both: a missing fixture stays missing, and an open breaker
+// would hide the fixtures that do exist.
+func OutboundOptions(cfg *config.Config) httpclient.Options {
+	if cfg.Outbound.Mode == fixtures.Replay {
+		return httpclient.Options{Fallback: httpclient.Upstream{Timeout: cfg.Outbound.Timeout}}
+	}
+	return httpclient.Options{
+		Retries:          cfg.Outbound.Retries,
+		BaseDelay:        cfg.Outbound.RetryBaseDelay,
+		MaxDelay:         cfg.Outbound.RetryMaxDelay,
+		Fallback:         httpclient.Upstream{Timeout: cfg.Outbound.Timeout},
+		BreakerThreshold: cfg.Outbound.BreakerThreshold,
+		BreakerCooldown:  cfg.Outbound.BreakerCooldown,
+	}
+}
+
+// volatileParams are query parameters left out of fixtures: the Visual
+// Crossing key, the USGS search window, which ends today, and the World Bank
+// lookback window, which ends this year.
+var volatileParams = []string{"key", "starttime", "endtime", "date"}
+
+// UpstreamTransport returns the RoundTripper at the bottom of the outbound
+// stack for the configured upstream mode: next when live, next recording
+// fixtures, or recorded fixtures with no network access.
+func UpstreamTransport(cfg *config.Config, next http.RoundTripper) http.RoundTripper {
+	store := fixtures.NewStore(cfg.Outbound.FixturesDir, volatileParams...)
+	store.Fallback = cfg.Outbound.FixturesFallback
+	return store.Transport(cfg.Outbound.Mode, next)
+}
@@ -1,27 +1,23 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/alerts"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	"github.com/publicthrone547/towards_project/internal/countries"
+	"github.com/publicthrone547/towards_project/internal/logging"
+	"github.com/publicthrone547/towards_project/internal/models"
 )
 
-var visualCrossingKey = "SKL8Z6DG99ASZ66YWBJHPH3S7"
-
-var geminiAPIKey string
-
-func InitGemini(apiKey string) {
-	geminiAPIKey = apiKey
-}
-
 type WeatherResponse struct {
 	City              string                   `json:"city"`
 	Temperature       float64                  `json:"temperature"`
@@ -35,7 +31,13 @@ type WeatherResponse struct {
 	TempMin           float64                  `json:"temp_min,omitempty"`
 	Humidity          float64                  `json:"humidity,omitempty"`
 	WindSpeed         float64                  `json:"wind_speed,omitempty"`
-	Hours             []interface{}            `json:"hours,omitempty"`
+	FeelsLike         float64                  `json:"feels_like,omitempty"`
+	HeatIndex         float64                  `json:"heat_index,omitempty"`
+	WindChill         float64                  `json:"wind_chill,omitempty"`
+	Humidex           float64                  `json:"humidex,omitempty"`
+	UTCI              float64                  `json:"utci,omitempty"`
+	Hours             []models.HourlyWeather   `json:"hours,omitempty"`
+	BestHours         []string                 `json:"best_hours,omitempty"`
 	AIForecast        string                   `json:"ai_forecast,omitempty"`
 	GDPUSD            float64                  `json:"gdp_usd,omitempty"`
 	PopulationTotal   int64                    `json:"population_total,omitempty"`
@@ -46,411 +48,318 @@ type WeatherResponse struct {
 	EarthquakeRisk    float64                  `json:"earthquake_risk,omitempty"`
 	EarthquakeCount   int                      `json:"earthquake_count,omitempty"`
 	EarthquakeMaxMag  float64                  `json:"earthquake_max_mag,omitempty"`
+	EarthquakeMag7d   float64                  `json:"earthquake_max_mag_7d,omitempty"`
 	RecentQuakes      []map[string]interface{} `json:"recent_quakes,omitempty"`
+	Alerts            []alerts.Alert           `json:"alerts"`
+	// ForecastErr is why AIForecast is missing when generating it failed.
+	// Each API renders it in its own shape.
+	ForecastErr error `json:"-"`
 }
 
-func GetWeather(c *gin.Context) {
-	city := c.Query("city")
-	if city == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "city query param required"})
+type AlertsResponse struct {
+	City   string         `json:"city"`
+	Date   string         `json:"date"`
+	Alerts []alerts.Alert `json:"alerts"`
+}
+
+func (s *Server) GetWeather(c *gin.Context) {
+	out, err := s.Weather(c.Request.Context(), c.Query("city"), c.Query("date"))
+	if err != nil {
+		apierror.Respond(c, err)
 		return
 	}
-
-	if k := os.Getenv("VISUAL_CROSSING_KEY"); k != "" {
-		visualCrossingKey = k
+	var skipped *apierror.Error
+	switch {
+	case out.ForecastErr == nil:
+	case errors.As(out.ForecastErr, &skipped):
+		// The caller's own limits, such as its AI quota, not a Gemini failure.
+		out.AIForecast = "forecast skipped: " + skipped.Detail
+	default:
+		out.AIForecast = "gemini error: " + errorDetail(out.ForecastErr)
 	}
+	c.JSON(http.StatusOK, out)
+}
 
-	base := "https://weather.visualcrossing.com/VisualCrossingWebServices/rest/services/timeline/"
+// Weather loads weather for city on date (today when empty) and adds the AI
+// forecast for today and future days. A failed forecast does not fail the
+// call and is left in ForecastErr for the caller to render.
+func (s *Server) Weather(ctx context.Context, city, date string) (*WeatherResponse, error) {
+	if city == "" {
+		return nil, apierror.New(apierror.CodeMissingParameter, "city query param required")
+	}
 
-	dateParam := c.Query("date")
-	var u string
-	var respDate time.Time
+	out, day, err := s.loadWeather(ctx, city, date)
+	if err != nil {
+		return nil, err
+	}
 
-	ensureGeminiKey := func() string {
-		if geminiAPIKey != "" {
-			return geminiAPIKey
-		}
-		return os.Getenv("GEMINI_API_KEY")
+	if !s.forecastWanted(day) {
+		return out, nil
+	}
+	// The route is counted as a weather call; the forecast also costs the
+	// caller's AI quota, and is skipped when that is used up.
+	if err := auth.Charge(ctx, auth.KindAI); err != nil {
+		out.ForecastErr = err
+		return out, nil
 	}
+	aiText, err := s.aiForecast(ctx, out, day)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("AI forecast: %v", err)
+		out.ForecastErr = err
+		return out, nil
+	}
+	out.AIForecast = aiText
+	return out, nil
+}
 
-	if dateParam != "" {
-		var parsed time.Time
-		var err error
-		parsed, err = time.Parse("02-01-2006", dateParam)
-		if err != nil {
-			parsed, err = time.Parse("2006-01-02", dateParam)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "date must be DD-MM-YYYY or YYYY-MM-DD"})
-				return
-			}
-		}
-		respDate = parsed
+// forecastWanted reports whether an AI forecast is generated for day: only
+// for today and later days, and only when Gemini is configured.
+func (s *Server) forecastWanted(day time.Time) bool {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(today) && s.ai.Configured()
+}
 
-		u = base + url.PathEscape(city) + "/" + parsed.Format("2006-01-02") + "?unitGroup=metric&include=days&key=" + url.QueryEscape(visualCrossingKey) + "&contentType=json"
+// aiForecast generates a one-line forecast that quotes the metrics in out.
+func (s *Server) aiForecast(ctx context.Context, out *WeatherResponse, day time.Time) (string, error) {
+	instruction := "You are an assistant that generates a short weather forecast and a brief day comfort summary in English. " +
+		"You MUST use and PRESERVE the numeric values provided in the prompt exactly, and insert them into a readable sentence. " +
+		"Response format: one short line (not JSON) containing the temperature (°C), main conditions, humidity (%) and wind speed (m/s), " +
+		"plus a short tip (what to take/how to dress). The numeric values in the sentence must exactly match those in the prompt."
 
-		resp, err := http.Get(u)
-		if err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch from visualcrossing", "detail": err.Error()})
-			return
-		}
-		defer resp.Body.Close()
+	prompt := fmt.Sprintf("City: %s\nDate: %s (Year: %d)\nTemperature_max: %.1f\nHumidity: %.1f\nWindSpeed: %.1f\nAirPurity: %d\nRoadTraffic: %d\nCrimeRisks: %d\nLifeComfortIndex: %.1f\nConditions: %s",
+		out.City, day.Format("2006-01-02"), day.Year(), out.Temperature, out.Humidity, out.WindSpeed, out.AirPurity, out.RoadTraffic, out.CrimeRisks, out.LifeComfortIdx, out.Conditions)
 
-		if resp.StatusCode != http.StatusOK {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "visualcrossing returned non-200", "status": resp.Status})
-			return
-		}
+	return s.ai.Generate(ctx, instruction, prompt)
+}
 
-		var body map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode response", "detail": err.Error()})
-			return
-		}
+func (s *Server) GetAlerts(c *gin.Context) {
+	city := c.Query("city")
+	if city == "" {
+		apierror.Respond(c, apierror.New(apierror.CodeMissingParameter, "city query param required"))
+		return
+	}
 
-		var tempMax, tempMin, hum, wind, pressure float64
-		var cond string
-		var hours []interface{}
-		if days, ok := body["days"].([]interface{}); ok && len(days) > 0 {
-			if dm, ok := days[0].(map[string]interface{}); ok {
-				if v, ok := dm["tempmax"].(float64); ok {
-					tempMax = v
-				}
-				if v, ok := dm["tempmin"].(float64); ok {
-					tempMin = v
-				}
-				if tempMax == 0 {
-					if v, ok := dm["temp"].(float64); ok {
-						tempMax = v
-					}
-				}
-				if v, ok := dm["humidity"].(float64); ok {
-					hum = v
-				}
-				if v, ok := dm["windspeed"].(float64); ok {
-					wind = v
-				}
-				if v, ok := dm["pressure"].(float64); ok {
-					pressure = v
-				} else if pn, ok := dm["pressure"].(json.Number); ok {
-					if fv, err := pn.Float64(); err == nil {
-						pressure = fv
-					}
-				}
-				if cnd, ok := dm["conditions"].(string); ok {
-					cond = cnd
-				}
-				if h, ok := dm["hours"].([]interface{}); ok {
-					hours = h
-				}
-			}
-		} else {
-			c.JSON(http.StatusBadGateway, gin.H{"error": "visualcrossing returned no day data for that date"})
-			return
-		}
+	out, _, err := s.loadWeather(c.Request.Context(), city, c.Query("date"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
 
-		airScore := getAirScore(city)
-		trafficScore := getTrafficScore(city)
-		crimeScore := getCrimeScore(city)
+	c.JSON(http.StatusOK, AlertsResponse{City: out.City, Date: out.Date, Alerts: out.Alerts})
+}
 
-		tempForIndex := tempMax
-		tempDiff := tempForIndex - 21.0
-		if tempDiff < 0 {
-			tempDiff = -tempDiff
-		}
-		tempPenalty := tempDiff * 5.0
-		tempScore := 100.0 - tempPenalty
-		if tempScore < 0 {
-			tempScore = 0
-		}
-		if tempScore > 100 {
-			tempScore = 100
-		}
+// collectWeather fetches weather for city (today when dateParam is empty),
+// enriches it with country, city and earthquake data, computes the comfort
+// index and evaluates alerts. The AI forecast is left to the caller. The
+// returned time is the UTC day the data refers to.
+func (s *Server) collectWeather(ctx context.Context, city, dateParam string) (*WeatherResponse, time.Time, error) {
+	out, body, day, err := s.fetchWeather(ctx, city, dateParam)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
 
-		trafficComfort := 100 - trafficScore
-		if trafficComfort < 0 {
-			trafficComfort = 0
+	// Enrichments are best effort: failures are logged and the fields left empty.
+	logger := logging.FromContext(ctx).WithField("city", city)
+	country := s.getCountryFromBody(ctx, body)
+	if country != "" {
+		if gdp, pop, dens, err := s.fetchCountryStats(ctx, country); err == nil {
+			out.GDPUSD = gdp
+			out.PopulationTotal = pop
+			out.PopulationDensity = dens
+		} else {
+			logger.Warnf("country stats for %s: %v", country, err)
 		}
-		crimeComfort := 100 - crimeScore
-		if crimeComfort < 0 {
-			crimeComfort = 0
+		if cp, carea, err := s.fetchCityStats(ctx, out.City, country); err == nil {
+			out.CityPopulation = cp
+			if carea > 0 {
+				out.CityDensity = float64(cp) / carea
+			}
+		} else {
+			logger.Warnf("city stats: %v", err)
 		}
+	}
 
-		total := (tempScore + float64(airScore) + float64(trafficComfort) + float64(crimeComfort)) / 4.0
-		if total < 0 {
-			total = 0
-		}
-		if total > 100 {
-			total = 100
+	if lat, lon, ok := coordinates(body); ok {
+		if q, err := s.fetchEarthquakeRisk(ctx, lat, lon, 100, 30); err == nil {
+			out.EarthquakeRisk = q.Risk
+			out.EarthquakeCount = q.Count
+			out.EarthquakeMaxMag = q.MaxMag
+			out.EarthquakeMag7d = q.MaxMagLastWeek
+			out.RecentQuakes = q.Recent
+		} else {
+			logger.Warnf("earthquake risk: %v", err)
 		}
+	}
 
-		out := WeatherResponse{
-			City:           city,
-			Temperature:    tempMax,
-			Conditions:     cond,
-			AirPurity:      airScore,
-			RoadTraffic:    trafficScore,
-			CrimeRisks:     crimeScore,
-			LifeComfortIdx: total,
-			Date:           respDate.Format("02-01-2006"),
-			TempMax:        tempMax,
-			TempMin:        tempMin,
-			Humidity:       hum,
-			WindSpeed:      wind,
-			Pressure:       pressure,
-			Hours:          hours,
-		}
+	out.Alerts = s.alerts.Evaluate(alertMetrics(out))
+	return out, day, nil
+}
 
-		// Try to fetch country stats
-		country := getCountryFromBody(body)
-		if country != "" {
-			if gdp, pop, dens, err := fetchCountryStats(country); err == nil {
-				out.GDPUSD = gdp
-				out.PopulationTotal = pop
-				out.PopulationDensity = dens
-			}
-			if cp, carea, err := fetchCityStats(city, country); err == nil {
-				out.CityPopulation = cp
-				if carea > 0 {
-					out.CityDensity = float64(cp) / carea
-				}
-			}
-		}
+// fetchWeather fetches the Visual Crossing timeline for city and derives the
+// thermal and comfort metrics, which need no further upstream calls. body is
+// the raw timeline response, from which enrichments locate the city.
+func (s *Server) fetchWeather(ctx context.Context, city, dateParam string) (*WeatherResponse, map[string]interface{}, time.Time, error) {
+	key := s.cfg.Weather.APIKey
 
-		// Earthquake data: use lat/lon if available
-		if latv, lok := body["latitude"].(float64); lok {
-			if lonv, lok2 := body["longitude"].(float64); lok2 {
-				if risk, cnt, maxm, rec, err := fetchEarthquakeRisk(latv, lonv, 100, 30); err == nil {
-					out.EarthquakeRisk = risk
-					out.EarthquakeCount = cnt
-					out.EarthquakeMaxMag = maxm
-					out.RecentQuakes = rec
-				}
+	base := endpoint(s.cfg.Weather.BaseURL, "timeline/")
+
+	var out *WeatherResponse
+	var body map[string]interface{}
+	var day time.Time
+
+	if dateParam != "" {
+		parsed, err := time.Parse("02-01-2006", dateParam)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", dateParam)
+			if err != nil {
+				return nil, nil, time.Time{}, apierror.New(apierror.CodeInvalidDate, "date must be DD-MM-YYYY or YYYY-MM-DD, got %q", dateParam)
 			}
 		}
+		day = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC)
 
-		now := time.Now().UTC()
-		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-		parsedDate := time.Date(respDate.Year(), respDate.Month(), respDate.Day(), 0, 0, 0, 0, time.UTC)
-		if parsedDate.Before(today) {
-			c.JSON(http.StatusOK, out)
-			return
+		u := base + url.PathEscape(city) + "/" + parsed.Format("2006-01-02") + "?unitGroup=metric&include=days,hours&key=" + url.QueryEscape(key) + "&contentType=json"
+		body, err = s.fetchVisualCrossing(ctx, city, u)
+		if err != nil {
+			return nil, nil, time.Time{}, err
+		}
+		out, err = weatherFromDay(city, body)
+		if err != nil {
+			return nil, nil, time.Time{}, err
 		}
+		out.Date = parsed.Format("02-01-2006")
+	} else {
+		now := time.Now()
+		day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 
-		apiKey := ensureGeminiKey()
-		if apiKey == "" {
-			c.JSON(http.StatusOK, out)
-			return
+		// Hours only come with the days, under days[0].hours.
+		u := base + url.PathEscape(city) + "?unitGroup=metric&include=current,days,hours&key=" + url.QueryEscape(key) + "&contentType=json"
+		var err error
+		body, err = s.fetchVisualCrossing(ctx, city, u)
+		if err != nil {
+			return nil, nil, time.Time{}, err
 		}
+		out = weatherFromCurrent(body)
+		out.Date = now.Format("02-01-2006")
+	}
 
-		instruction := "You are an assistant that generates a short weather forecast and a brief day comfort summary in English. " +
-			"You MUST use and PRESERVE the numeric values provided in the prompt exactly, and insert them into a readable sentence. " +
-			"Response format: one short line (not JSON) containing the temperature (°C), main conditions, humidity (%) and wind speed (m/s), " +
-			"plus a short tip (what to take/how to dress). The numeric values in the sentence must exactly match those in the prompt."
+	airScore := getAirScore(city)
+	trafficScore := getTrafficScore(city)
+	crimeScore := getCrimeScore(city)
 
-		prompt := fmt.Sprintf("City: %s\nDate: %s (Year: %d)\nTemperature_max: %.1f\nHumidity: %.1f\nWindSpeed: %.1f\nAirPurity: %d\nRoadTraffic: %d\nCrimeRisks: %d\nLifeComfortIndex: %.1f\nConditions: %s",
-			city, respDate.Format("2006-01-02"), respDate.Year(), tempMax, hum, wind, airScore, trafficScore, crimeScore, total, cond)
+	thermal := computeThermalIndices(out.Temperature, out.Humidity, out.WindSpeed)
+	out.AirPurity = airScore
+	out.RoadTraffic = trafficScore
+	out.CrimeRisks = crimeScore
+	out.LifeComfortIdx = lifeComfortIndex(temperatureScore(thermal.UTCI), airScore, trafficScore, crimeScore)
+	out.FeelsLike = thermal.FeelsLike
+	out.HeatIndex = thermal.HeatIndex
+	out.WindChill = thermal.WindChill
+	out.Humidex = thermal.Humidex
+	out.UTCI = thermal.UTCI
+	out.BestHours = bestHours(out.Hours, 3)
+	return out, body, day, nil
+}
 
-		aiText, err := askGemini(apiKey, instruction, prompt)
-		if err != nil {
-			out.AIForecast = fmt.Sprintf("gemini error: %v", err)
-			c.JSON(http.StatusOK, out)
-			return
-		}
+// coordinates returns the location Visual Crossing resolved the city to.
+func coordinates(body map[string]interface{}) (lat, lon float64, ok bool) {
+	lat, latOK := body["latitude"].(float64)
+	lon, lonOK := body["longitude"].(float64)
+	return lat, lon, latOK && lonOK
+}
 
-		out.AIForecast = aiText
-		c.JSON(http.StatusOK, out)
-		return
+func alertMetrics(w *WeatherResponse) alerts.Metrics {
+	return alerts.Metrics{
+		alerts.MetricTemperature:    w.Temperature,
+		alerts.MetricFeelsLike:      w.FeelsLike,
+		alerts.MetricUTCI:           w.UTCI,
+		alerts.MetricWindSpeedMs:    w.WindSpeed / 3.6,
+		alerts.MetricHumidity:       w.Humidity,
+		alerts.MetricAirPurity:      float64(w.AirPurity),
+		alerts.MetricComfortIndex:   w.LifeComfortIdx,
+		alerts.MetricQuakeMaxMag7d:  w.EarthquakeMag7d,
+		alerts.MetricEarthquakeRisk: w.EarthquakeRisk,
 	}
+}
 
-	u = base + url.PathEscape(city) + "?unitGroup=metric&include=current&key=" + url.QueryEscape(visualCrossingKey) + "&contentType=json"
-	resp, err := http.Get(u)
-	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch from visualcrossing", "detail": err.Error()})
-		return
+func (s *Server) fetchVisualCrossing(ctx context.Context, city, u string) (map[string]interface{}, error) {
+	var body map[string]interface{}
+	if err := s.getJSON(ctx, u, nil, &body); err != nil {
+		return nil, weatherUpstreamError(city, err)
 	}
-	defer resp.Body.Close()
+	return body, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "visualcrossing returned non-200", "status": resp.Status})
-		return
+// weatherFromDay reads the first day of a date-specific timeline response.
+func weatherFromDay(city string, body map[string]interface{}) (*WeatherResponse, error) {
+	days, ok := body["days"].([]interface{})
+	if !ok || len(days) == 0 {
+		return nil, apierror.New(apierror.CodeUpstreamWeatherInvalid, "weather provider returned no day data for that date")
 	}
 
-	var body map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decode response", "detail": err.Error()})
-		return
+	out := &WeatherResponse{City: city}
+	if dm, ok := days[0].(map[string]interface{}); ok {
+		out.TempMax = floatField(dm, "tempmax")
+		out.TempMin = floatField(dm, "tempmin")
+		if out.TempMax == 0 {
+			out.TempMax = floatField(dm, "temp")
+		}
+		out.Temperature = out.TempMax
+		out.Humidity = floatField(dm, "humidity")
+		out.WindSpeed = floatField(dm, "windspeed")
+		out.Pressure = floatField(dm, "pressure")
+		if cnd, ok := dm["conditions"].(string); ok {
+			out.Conditions = cnd
+		}
+		if h, ok := dm["hours"].([]interface{}); ok {
+			out.Hours = parseVisualCrossingHours(h)
+		}
 	}
+	return out, nil
+}
 
+// weatherFromCurrent reads current conditions, falling back to the first day
+// for values the current block lacks. Hours always come from the first day.
+func weatherFromCurrent(body map[string]interface{}) *WeatherResponse {
 	cityName, _ := body["resolvedAddress"].(string)
+	out := &WeatherResponse{City: cityName}
 	temp := 0.0
-	cond := ""
-	var tempMax float64
-	var tempMin float64
-	var hum float64
-	var wind float64
-	var pressure float64
-	var hours []interface{}
 
 	if curr, ok := body["currentConditions"].(map[string]interface{}); ok {
-		if t, ok := curr["temp"].(float64); ok {
-			temp = t
-		} else if tf, ok := curr["temp"].(json.Number); ok {
-			if fv, err := tf.Float64(); err == nil {
-				temp = fv
-			}
-		}
+		temp = floatField(curr, "temp")
 		if cnd, ok := curr["conditions"].(string); ok {
-			cond = cnd
-		}
-		if v, ok := curr["humidity"].(float64); ok {
-			hum = v
-		}
-		if v, ok := curr["windspeed"].(float64); ok {
-			wind = v
-		}
-		if v, ok := curr["pressure"].(float64); ok {
-			pressure = v
-		} else if pn, ok := curr["pressure"].(json.Number); ok {
-			if fv, err := pn.Float64(); err == nil {
-				pressure = fv
-			}
-		}
-		if h, ok := curr["hours"].([]interface{}); ok {
-			hours = h
+			out.Conditions = cnd
 		}
+		out.Humidity = floatField(curr, "humidity")
+		out.WindSpeed = floatField(curr, "windspeed")
+		out.Pressure = floatField(curr, "pressure")
 	}
 
 	if days, ok := body["days"].([]interface{}); ok && len(days) > 0 {
 		if dm, ok := days[0].(map[string]interface{}); ok {
-			if v, ok := dm["tempmax"].(float64); ok {
-				tempMax = v
-			}
-			if v, ok := dm["tempmin"].(float64); ok {
-				tempMin = v
-			}
+			out.TempMax = floatField(dm, "tempmax")
+			out.TempMin = floatField(dm, "tempmin")
 			if temp == 0 {
 				if tv, ok := dm["temp"].(float64); ok {
 					temp = tv
-				} else {
-					if tempMax != 0 || tempMin != 0 {
-						temp = (tempMax + tempMin) / 2.0
-					}
+				} else if out.TempMax != 0 || out.TempMin != 0 {
+					temp = (out.TempMax + out.TempMin) / 2.0
 				}
 			}
-			if cnd, ok := dm["conditions"].(string); ok && cond == "" {
-				cond = cnd
-			}
-			if h, ok := dm["hours"].([]interface{}); ok && len(hours) == 0 {
-				hours = h
-			}
-		}
-	}
-
-	tempToReturn := temp
-	if tempMax != 0 {
-		tempToReturn = tempMax
-	}
-
-	airScore := getAirScore(city)
-	trafficScore := getTrafficScore(city)
-	crimeScore := getCrimeScore(city)
-
-	tempForIndex := tempToReturn
-	tempDiff := tempForIndex - 21.0
-	if tempDiff < 0 {
-		tempDiff = -tempDiff
-	}
-	tempPenalty := tempDiff * 5.0
-	tempScore := 100.0 - tempPenalty
-	if tempScore < 0 {
-		tempScore = 0
-	}
-	if tempScore > 100 {
-		tempScore = 100
-	}
-
-	trafficComfort := 100 - trafficScore
-	if trafficComfort < 0 {
-		trafficComfort = 0
-	}
-	crimeComfort := 100 - crimeScore
-	if crimeComfort < 0 {
-		crimeComfort = 0
-	}
-
-	total := (tempScore + float64(airScore) + float64(trafficComfort) + float64(crimeComfort)) / 4.0
-	if total < 0 {
-		total = 0
-	}
-	if total > 100 {
-		total = 100
-	}
-
-	out := WeatherResponse{
-		City:           cityName,
-		Temperature:    tempToReturn,
-		Conditions:     cond,
-		AirPurity:      airScore,
-		RoadTraffic:    trafficScore,
-		CrimeRisks:     crimeScore,
-		LifeComfortIdx: total,
-		Date:           time.Now().Format("02-01-2006"),
-		TempMax:        tempMax,
-		TempMin:        tempMin,
-		Humidity:       hum,
-		WindSpeed:      wind,
-		Pressure:       pressure,
-		Hours:          hours,
-	}
-
-	country := getCountryFromBody(body)
-	if country != "" {
-		if gdp, pop, dens, err := fetchCountryStats(country); err == nil {
-			out.GDPUSD = gdp
-			out.PopulationTotal = pop
-			out.PopulationDensity = dens
-		}
-		if cp, carea, err := fetchCityStats(cityName, country); err == nil {
-			out.CityPopulation = cp
-			if carea > 0 {
-				out.CityDensity = float64(cp) / carea
+			if cnd, ok := dm["conditions"].(string); ok && out.Conditions == "" {
+				out.Conditions = cnd
 			}
-		}
-	}
-
-	if latv, lok := body["latitude"].(float64); lok {
-		if lonv, lok2 := body["longitude"].(float64); lok2 {
-			if risk, cnt, maxm, rec, err := fetchEarthquakeRisk(latv, lonv, 100, 30); err == nil {
-				out.EarthquakeRisk = risk
-				out.EarthquakeCount = cnt
-				out.EarthquakeMaxMag = maxm
-				out.RecentQuakes = rec
+			if h, ok := dm["hours"].([]interface{}); ok {
+				out.Hours = parseVisualCrossingHours(h)
 			}
 		}
 	}
 
-	apiKey := ensureGeminiKey()
-	if apiKey == "" {
-		c.JSON(http.StatusOK, out)
-		return
+	out.Temperature = temp
+	if out.TempMax != 0 {
+		out.Temperature = out.TempMax
 	}
-
-	instruction := "You are an assistant that generates a short weather forecast and a brief day comfort summary in English. " +
-		"You MUST use and PRESERVE the numeric values provided in the prompt exactly, and insert them into a readable sentence. " +
-		"Response format: one short line (not JSON) containing the temperature (°C), main conditions, humidity (%) and wind speed (m/s), " +
-		"plus a short tip (what to take/how to dress). The numeric values in the sentence must exactly match those in the prompt."
-
-	prompt := fmt.Sprintf("City: %s\nDate: %s (Year: %d)\nTemperature_max: %.1f\nHumidity: %.1f\nWindSpeed: %.1f\nAirPurity: %d\nRoadTraffic: %d\nCrimeRisks: %d\nLifeComfortIndex: %.1f\nConditions: %s",
-		cityName, time.Now().Format("2006-01-02"), time.Now().Year(), tempToReturn, hum, wind, airScore, trafficScore, crimeScore, total, cond)
-
-	aiText, err := askGemini(apiKey, instruction, prompt)
-	if err != nil {
-		out.AIForecast = fmt.Sprintf("gemini error: %v", err)
-		c.JSON(http.StatusOK, out)
-		return
-	}
-
-	out.AIForecast = aiText
-	c.JSON(http.StatusOK, out)
+	return out
 }
 
 func fetchCountryFromResolvedAddress(addr string) string {
@@ -462,23 +371,26 @@ func fetchCountryFromResolvedAddress(addr string) string {
 	return last
 }
 
-func fetchCountryStats(country string) (float64, int64, float64, error) {
+type countryStats struct {
+	GDP        float64
+	Population int64
+	Density    float64
+}
+
+func (s *Server) fetchCountryStats(ctx context.Context, country string) (float64, int64, float64, error) {
 	if country == "" {
 		return 0, 0, 0, fmt.Errorf("country empty")
 	}
 
-	rcURL := "https://restcountries.com/v3.1/name/" + url.PathEscape(country)
-	resp, err := http.Get(rcURL)
-	if err != nil {
-		return 0, 0, 0, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, 0, fmt.Errorf("restcountries returned %s", resp.Status)
+	cacheKey := "country:" + strings.ToLower(country)
+	if v, ok := s.cache.Get(cacheKey); ok {
+		cs := v.(countryStats)
+		return cs.GDP, cs.Population, cs.Density, nil
 	}
+
 	var rc []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&rc); err != nil {
-		return 0, 0, 0, err
+	if err := s.getJSON(ctx, endpoint(s.cfg.Countries.BaseURL, "name/"+url.PathEscape(country)), nil, &rc); err != nil {
+		return 0, 0, 0, fmt.Errorf("restcountries: %w", err)
 	}
 	if len(rc) == 0 {
 		return 0, 0, 0, fmt.Errorf("no country data")
@@ -493,23 +405,19 @@ func fetchCountryStats(country string) (float64, int64, float64, error) {
 	}
 
 	var gdp float64
+	cacheable := true
 	if cca3, ok := rc[0]["cca3"].(string); ok && cca3 != "" {
-		wbURL := fmt.Sprintf("https://api.worldbank.org/v2/country/%s/indicator/NY.GDP.MKTP.CD?format=json&per_page=1", strings.ToLower(cca3))
-		wbResp, err := http.Get(wbURL)
-		if err == nil {
-			defer wbResp.Body.Close()
-			if wbResp.StatusCode == http.StatusOK {
-				var wb []interface{}
-				if err := json.NewDecoder(wbResp.Body).Decode(&wb); err == nil && len(wb) > 1 {
-					if series, ok := wb[1].([]interface{}); ok && len(series) > 0 {
-						if first, ok := series[0].(map[string]interface{}); ok {
-							if val, ok := first["value"].(float64); ok {
-								gdp = val
-							}
-						}
-					}
-				}
-			}
+		// The latest year is often still null; Latest walks back to the
+		// newest published value.
+		v, err := s.countries.Latest(ctx, strings.ToLower(cca3), countries.GDP)
+		switch {
+		case err != nil:
+			// The rest is still returned, but not cached, so a transient
+			// World Bank failure does not hide the GDP for the cache's TTL.
+			logging.FromContext(ctx).Warnf("world bank GDP for %s: %v", cca3, err)
+			cacheable = false
+		case v != nil:
+			gdp = v.Value
 		}
 	}
 
@@ -518,29 +426,31 @@ func fetchCountryStats(country string) (float64, int64, float64, error) {
 		density = float64(population) / area
 	}
 
+	if cacheable {
+		s.cache.Set(cacheKey, countryStats{GDP: gdp, Population: population, Density: density})
+	}
 	return gdp, population, density, nil
 }
 
-func fetchCityStats(city, country string) (int64, float64, error) {
+func (s *Server) nominatimHeader() http.Header {
+	return http.Header{"User-Agent": {s.cfg.Geocoding.UserAgent}}
+}
+
+func (s *Server) fetchCityStats(ctx context.Context, city, country string) (int64, float64, error) {
 	if city == "" {
 		return 0, 0, fmt.Errorf("city empty")
 	}
-	q := url.QueryEscape(city + ", " + country)
-	nomURL := "https://nominatim.openstreetmap.org/search?format=json&limit=1&q=" + q + "&addressdetails=1&extratags=1"
-	req, _ := http.NewRequest("GET", nomURL, nil)
-	req.Header.Set("User-Agent", "towards_project/1.0")
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, 0, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("nominatim returned %s", resp.Status)
+
+	cacheKey := "city:" + strings.ToLower(city+", "+country)
+	if v, ok := s.cache.Get(cacheKey); ok {
+		return v.(int64), 0, nil
 	}
+
+	q := url.QueryEscape(city + ", " + country)
+	nomURL := endpoint(s.cfg.Geocoding.BaseURL, "search?format=json&limit=1&q="+q+"&addressdetails=1&extratags=1")
 	var res []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return 0, 0, err
+	if err := s.getJSON(ctx, nomURL, s.nominatimHeader(), &res); err != nil {
+		return 0, 0, fmt.Errorf("nominatim: %w", err)
 	}
 	if len(res) == 0 {
 		return 0, 0, fmt.Errorf("no nominatim result")
@@ -555,99 +465,45 @@ func fetchCityStats(city, country string) (int64, float64, error) {
 			}
 		}
 	}
+	s.cache.Set(cacheKey, pop)
 	return pop, area, nil
 }
 
-func getCountryFromBody(body map[string]interface{}) string {
+func (s *Server) getCountryFromBody(ctx context.Context, body map[string]interface{}) string {
 	if addr, ok := body["resolvedAddress"].(string); ok && addr != "" {
 		return fetchCountryFromResolvedAddress(addr)
 	}
 	lat, latOk := body["latitude"].(float64)
 	lon, lonOk := body["longitude"].(float64)
 	if latOk && lonOk {
-		if c := nominatimReverse(lat, lon); c != "" {
+		if c := s.nominatimReverse(ctx, lat, lon); c != "" {
 			return c
 		}
 	}
 	return ""
 }
 
-func nominatimReverse(lat, lon float64) string {
-	url := fmt.Sprintf("https://nominatim.openstreetmap.org/reverse?format=json&lat=%.6f&lon=%.6f&zoom=3&addressdetails=1", lat, lon)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("User-Agent", "towards_project/1.0")
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return ""
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return ""
+func (s *Server) nominatimReverse(ctx context.Context, lat, lon float64) string {
+	u := endpoint(s.cfg.Geocoding.BaseURL, fmt.Sprintf("reverse?format=json&lat=%.6f&lon=%.6f&zoom=3&addressdetails=1", lat, lon))
+
+	cacheKey := fmt.Sprintf("reverse:%.3f,%.3f", lat, lon)
+	if v, ok := s.cache.Get(cacheKey); ok {
+		return v.(string)
 	}
+
 	var res map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+	if err := s.getJSON(ctx, u, s.nominatimHeader(), &res); err != nil {
 		return ""
 	}
 	if addr, ok := res["address"].(map[string]interface{}); ok {
 		if country, ok := addr["country"].(string); ok {
+			s.cache.Set(cacheKey, country)
 			return country
 		}
 	}
 	return ""
 }
 
-func askGemini(apiKey, instruction, promt string) (string, error) {
-	url := "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
-
-	reqBody := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"parts": []map[string]string{
-					{"text": instruction},
-					{"text": promt},
-				},
-			},
-		},
-	}
-
-	data, _ := json.Marshal(reqBody)
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-goog-api-key", apiKey)
-
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	var res struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-	}
-	if err := json.Unmarshal(body, &res); err != nil {
-		return "", err
-	}
-
-	if len(res.Candidates) > 0 && len(res.Candidates[0].Content.Parts) > 0 {
-		return res.Candidates[0].Content.Parts[0].Text, nil
-	}
-	return "No response", nil
-}
-
 func getAirScore(city string) int {
 	if city == "" {
 		return 50
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/alerts"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+// WeatherResponseV2 is the /v2/weather shape: the flat v1 fields grouped into
+// nested metric objects, with typed earthquake entries. Groups without data
+// are omitted rather than zero-filled.
+type WeatherResponseV2 struct {
+	City        string                 `json:"city"`
+	Date        string                 `json:"date"`
+	Conditions  string                 `json:"conditions"`
+	Weather     WeatherMetrics         `json:"weather"`
+	Thermal     ThermalMetrics         `json:"thermal"`
+	Comfort     ComfortMetrics         `json:"comfort"`
+	Hours       []models.HourlyWeather `json:"hours"`
+	Country     *CountryMetrics        `json:"country,omitempty"`
+	CityStats   *CityMetrics           `json:"city_stats,omitempty"`
+	Earthquakes *EarthquakeMetrics     `json:"earthquakes,omitempty"`
+	Alerts      []alerts.Alert         `json:"alerts"`
+	Forecast    *AIForecast            `json:"forecast,omitempty"`
+}
+
+type WeatherMetrics struct {
+	Temperature float64 `json:"temperature"`
+	TempMin     float64 `json:"temp_min,omitempty"`
+	TempMax     float64 `json:"temp_max,omitempty"`
+	Humidity    float64 `json:"humidity"`
+	WindSpeed   float64 `json:"wind_speed"`
+	Pressure    float64 `json:"pressure,omitempty"`
+}
+
+type ThermalMetrics struct {
+	FeelsLike float64 `json:"feels_like"`
+	HeatIndex float64 `json:"heat_index"`
+	WindChill float64 `json:"wind_chill"`
+	Humidex   float64 `json:"humidex"`
+	UTCI      float64 `json:"utci"`
+}
+
+type ComfortMetrics struct {
+	Index       float64  `json:"index"`
+	AirPurity   int      `json:"air_purity"`
+	RoadTraffic int      `json:"road_traffic"`
+	CrimeRisks  int      `json:"crime_risks"`
+	BestHours   []string `json:"best_hours"`
+}
+
+type CountryMetrics struct {
+	GDPUSD            float64 `json:"gdp_usd,omitempty"`
+	Population        int64   `json:"population,omitempty"`
+	PopulationDensity float64 `json:"population_density,omitempty"`
+}
+
+type CityMetrics struct {
+	Population    int64   `json:"population"`
+	DensityPerKm2 float64 `json:"density_per_km2,omitempty"`
+}
+
+type EarthquakeMetrics struct {
+	Risk     float64      `json:"risk"`
+	Count    int          `json:"count"`
+	MaxMag   float64      `json:"max_mag"`
+	MaxMag7d float64      `json:"max_mag_7d"`
+	Recent   []Earthquake `json:"recent"`
+}
+
+type Earthquake struct {
+	Time      time.Time `json:"time"`
+	Magnitude float64   `json:"magnitude"`
+	Place     string    `json:"place"`
+}
+
+// AIForecast holds the generated summary, or the error code when generation
+// failed.
+type AIForecast struct {
+	Text      string        `json:"text,omitempty"`
+	ErrorCode apierror.Code `json:"error_code,omitempty"`
+}
+
+func (s *Server) GetWeatherV2(c *gin.Context) {
+	out, err := s.Weather(c.Request.Context(), c.Query("city"), c.Query("date"))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, WeatherV2(out))
+}
+
+// WeatherV2 converts a Weather result to the v2 shape. A ForecastErr is
+// reported as the forecast's error code.
+func WeatherV2(w *WeatherResponse) *WeatherResponseV2 {
+	out := weatherV2(w)
+	if w.ForecastErr != nil {
+		out.Forecast = &AIForecast{ErrorCode: aiUpstreamError(w.ForecastErr).Code}
+	}
+	return out
+}
+
+func weatherV2(w *WeatherResponse) *WeatherResponseV2 {
+	out := &WeatherResponseV2{
+		City:       w.City,
+		Date:       w.Date,
+		Conditions: w.Conditions,
+		Weather: WeatherMetrics{
+			Temperature: w.Temperature,
+			TempMin:     w.TempMin,
+			TempMax:     w.TempMax,
+			Humidity:    w.Humidity,
+			WindSpeed:   w.WindSpeed,
+			Pressure:    w.Pressure,
+		},
+		Thermal: ThermalMetrics{
+			FeelsLike: w.FeelsLike,
+			HeatIndex: w.HeatIndex,
+			WindChill: w.WindChill,
+			Humidex:   w.Humidex,
+			UTCI:      w.UTCI,
+		},
+		Comfort: ComfortMetrics{
+			Index:       w.LifeComfortIdx,
+			AirPurity:   w.AirPurity,
+			RoadTraffic: w.RoadTraffic,
+			CrimeRisks:  w.CrimeRisks,
+			BestHours:   w.BestHours,
+		},
+		Hours:  w.Hours,
+		Alerts: w.Alerts,
+	}
+	if out.Hours == nil {
+		out.Hours = []models.HourlyWeather{}
+	}
+	if out.Comfort.BestHours == nil {
+		out.Comfort.BestHours = []string{}
+	}
+	if w.GDPUSD != 0 || w.PopulationTotal != 0 {
+		out.Country = &CountryMetrics{GDPUSD: w.GDPUSD, Population: w.PopulationTotal, PopulationDensity: w.PopulationDensity}
+	}
+	if w.CityPopulation != 0 {
+		out.CityStats = &CityMetrics{Population: w.CityPopulation, DensityPerKm2: w.CityDensity}
+	}
+	if w.EarthquakeCount > 0 || w.EarthquakeRisk > 0 {
+		eq := &EarthquakeMetrics{
+			Risk:     w.EarthquakeRisk,
+			Count:    w.EarthquakeCount,
+			MaxMag:   w.EarthquakeMaxMag,
+			MaxMag7d: w.EarthquakeMag7d,
+			Recent:   []Earthquake{},
+		}
+		for _, q := range w.RecentQuakes {
+			eq.Recent = append(eq.Recent, earthquakeFromMap(q))
+		}
+		out.Earthquakes = eq
+	}
+	if w.AIForecast != "" {
+		out.Forecast = &AIForecast{Text: w.AIForecast}
+	}
+	return out
+}
+
+// earthquakeFromMap reads a v1 recent_quakes entry; time is USGS epoch millis.
+func earthquakeFromMap(m map[string]interface{}) Earthquake {
+	var q Earthquake
+	if ms, ok := m["time"].(float64); ok {
+		q.Time = time.UnixMilli(int64(ms)).UTC()
+	}
+	q.Magnitude, _ = m["mag"].(float64)
+	q.Place, _ = m["place"].(string)
+	return q
+}
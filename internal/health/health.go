@@ -0,0 +1,164 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// Check is a named readiness probe. A failing critical check makes the
+// service not ready; other failures only mark it degraded.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+type Result struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs checks concurrently and caches the report for ttl so probes
+// from load balancers do not hammer the database and upstreams.
+type Checker struct {
+	checks  []Check
+	ttl     time.Duration
+	timeout time.Duration
+
+	mu       sync.Mutex
+	cached   Report
+	cachedAt time.Time
+
+	draining atomic.Bool
+}
+
+func NewChecker(ttl, timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, ttl: ttl, timeout: timeout}
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing new
+// requests while in-flight ones finish.
+func (h *Checker) Drain() {
+	h.draining.Store(true)
+}
+
+func (h *Checker) Report(ctx context.Context) Report {
+	if h.draining.Load() {
+		return Report{Status: "draining"}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.cachedAt.IsZero() && time.Since(h.cachedAt) < h.ttl {
+		return h.cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make([]Result, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			start := time.Now()
+			err := c.Run(ctx)
+			r := Result{Status: "ok", Critical: c.Critical, LatencyMs: time.Since(start).Milliseconds(), CheckedAt: start.UTC()}
+			if err != nil {
+				r.Status = "fail"
+				r.Error = err.Error()
+			}
+			results[i] = r
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: "ok", Checks: map[string]Result{}}
+	for i, c := range h.checks {
+		r := results[i]
+		report.Checks[c.Name] = r
+		if r.Status == "fail" {
+			if c.Critical {
+				report.Status = "fail"
+			} else if report.Status == "ok" {
+				report.Status = "degraded"
+			}
+		}
+	}
+
+	h.cached = report
+	h.cachedAt = time.Now()
+	return report
+}
+
+// Liveness reports that the process is up and serving; it checks nothing else.
+func (h *Checker) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness responds 200 when all critical checks pass and 503 otherwise.
+func (h *Checker) Readiness(c *gin.Context) {
+	report := h.Report(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == "fail" || report.Status == "draining" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func DBCheck(db *sqlx.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			return db.PingContext(ctx)
+		},
+	}
+}
+
+// HTTPCheck reports an upstream reachable when it answers url with any HTTP
+// response below 500; authentication errors still prove reachability.
+func HTTPCheck(name string, client *http.Client, url string) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("User-Agent", "towards_project/1.0 readiness")
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 500 {
+				return &statusError{resp.Status}
+			}
+			return nil
+		},
+	}
+}
+
+type statusError struct {
+	status string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected status " + e.status
+}
@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned without contacting the upstream while its
+// circuit breaker is open.
+type CircuitOpenError struct {
+	Upstream string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return "circuit breaker open for " + e.Upstream
+}
+
+// breaker opens after threshold consecutive failures and rejects requests for
+// cooldown. It then lets a single probe through: success closes it, failure
+// opens it again.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// release ends a probe without counting it either way.
+func (b *breaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
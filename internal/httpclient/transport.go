@@ -0,0 +1,272 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/logging"
+)
+
+// Upstream is the policy applied to requests for one host.
+type Upstream struct {
+	// Name identifies the upstream in errors and logs; hosts sharing a name
+	// share a circuit breaker.
+	Name string
+	// Timeout bounds each attempt, from sending the request until the body
+	// is closed.
+	Timeout time.Duration
+	// MinInterval spaces requests to the upstream at least this far apart
+	// (Nominatim allows 1 req/s). Zero disables spacing.
+	MinInterval time.Duration
+	// RetryUnsafe allows retrying requests with non-idempotent methods, for
+	// POST APIs without side effects such as LLM generation.
+	RetryUnsafe bool
+	// Header is set on every request, replacing values of the same name.
+	Header http.Header
+}
+
+// Options configures retries and circuit breaking for all upstreams.
+type Options struct {
+	// Retries is the number of extra attempts after a failed one.
+	Retries   int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Fallback applies to hosts missing from the upstream table.
+	Fallback Upstream
+
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Transport is the shared outbound RoundTripper. Per upstream it enforces the
+// attempt timeout and request spacing, retries transport errors, 429 and 5xx
+// responses with jittered exponential backoff (honoring Retry-After), and
+// trips a circuit breaker after repeated failures.
+type Transport struct {
+	next      http.RoundTripper
+	opts      Options
+	upstreams map[string]Upstream
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+	spacers  map[string]*spacer
+}
+
+// NewTransport wraps next; upstreams is keyed by host, with the port when the
+// URL has one.
+func NewTransport(next http.RoundTripper, opts Options, upstreams map[string]Upstream) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		next:      next,
+		opts:      opts,
+		upstreams: upstreams,
+		breakers:  map[string]*breaker{},
+		spacers:   map[string]*spacer{},
+	}
+}
+
+func (t *Transport) upstream(host string) Upstream {
+	if u, ok := t.upstreams[host]; ok {
+		return u
+	}
+	u := t.opts.Fallback
+	u.Name = host
+	return u
+}
+
+func (t *Transport) state(name string) (*breaker, *spacer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.breakers[name]
+	if !ok {
+		b = &breaker{threshold: t.opts.BreakerThreshold, cooldown: t.opts.BreakerCooldown}
+		t.breakers[name] = b
+	}
+	s, ok := t.spacers[name]
+	if !ok {
+		s = &spacer{}
+		t.spacers[name] = s
+	}
+	return b, s
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	up := t.upstream(req.URL.Host)
+	b, sp := t.state(up.Name)
+	ctx := req.Context()
+
+	retries := t.opts.Retries
+	if !up.RetryUnsafe && !idempotent(req.Method) {
+		retries = 0
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := sp.wait(ctx, up.MinInterval); err != nil {
+			return nil, err
+		}
+		if !b.allow() {
+			return nil, &CircuitOpenError{Upstream: up.Name}
+		}
+
+		resp, err := t.attempt(req, attempt, up)
+		failed := err != nil || resp.StatusCode >= 500
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about the upstream.
+			b.release()
+		} else {
+			b.record(!failed)
+		}
+
+		retryable := failed || resp.StatusCode == http.StatusTooManyRequests
+		if !retryable || attempt >= retries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if ra, ok := retryAfter(resp); ok {
+				if ra > t.opts.MaxDelay {
+					// Waiting longer than we are willing to: hand the
+					// response to the caller instead.
+					return resp, nil
+				}
+				delay = ra
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		log := logging.FromContext(ctx).WithField("upstream", up.Name)
+		if err != nil {
+			log.Warnf("attempt %d failed: %v; retrying in %s", attempt+1, err, delay)
+		} else {
+			log.Warnf("attempt %d returned %s; retrying in %s", attempt+1, resp.Status, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends one copy of req with the upstream's headers, bounded by its
+// timeout. The timeout stays in force while the caller reads the body.
+func (t *Transport) attempt(req *http.Request, attempt int, up Upstream) (*http.Response, error) {
+	ctx := req.Context()
+	cancel := context.CancelFunc(func() {})
+	if up.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, up.Timeout)
+	}
+
+	r := req.Clone(ctx)
+	for name, values := range up.Header {
+		r.Header[name] = values
+	}
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff returns a full-jitter exponential delay for the given attempt.
+func (t *Transport) backoff(attempt int) time.Duration {
+	d := t.opts.BaseDelay << attempt
+	if d <= 0 || d > t.opts.MaxDelay {
+		d = t.opts.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// spacer serializes requests so consecutive ones start at least an interval
+// apart.
+type spacer struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (s *spacer) wait(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	now := time.Now()
+	start := s.next
+	if start.Before(now) {
+		start = now
+	}
+	s.next = start.Add(interval)
+	s.mu.Unlock()
+
+	if d := time.Until(start); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package httpclient_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/httpclient"
+)
+
+// script answers each request with the next of its responses, repeating the
+// last one, and keeps the time every request arrived.
+type script struct {
+	mu        sync.Mutex
+	responses []func() (*http.Response, error)
+	times     []time.Time
+	bodies    []string
+}
+
+func (s *script) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.times = append(s.times, time.Now())
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(b))
+	}
+	next := s.responses[0]
+	if len(s.responses) > 1 {
+		s.responses = s.responses[1:]
+	}
+	s.mu.Unlock()
+	return next()
+}
+
+func (s *script) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.times)
+}
+
+func status(code int, header ...string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		h := http.Header{}
+		for i := 0; i+1 < len(header); i += 2 {
+			h.Set(header[i], header[i+1])
+		}
+		return &http.Response{
+			StatusCode: code,
+			Status:     http.StatusText(code),
+			Header:     h,
+			Body:       io.NopCloser(strings.NewReader(http.StatusText(code))),
+		}, nil
+	}
+}
+
+func fail() (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func send(t *testing.T, rt http.RoundTripper, method, body string) (*http.Response, error) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, "http://upstream.test/path", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+var fast = httpclient.Options{Retries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		retryUnsafe bool
+		responses   []func() (*http.Response, error)
+		wantStatus  int
+		wantErr     bool
+		wantCalls   int
+	}{
+		{
+			name:       "503 then success",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(503), status(200)},
+			wantStatus: 200, wantCalls: 2,
+		},
+		{
+			name:       "transport error then success",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){fail, status(200)},
+			wantStatus: 200, wantCalls: 2,
+		},
+		{
+			name:       "retries exhausted",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(502)},
+			wantStatus: 502, wantCalls: 3,
+		},
+		{
+			name:       "429 honors short Retry-After",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(429, "Retry-After", "0"), status(200)},
+			wantStatus: 200, wantCalls: 2,
+		},
+		{
+			name:       "Retry-After above MaxDelay returns the response",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(503, "Retry-After", "120"), status(200)},
+			wantStatus: 503, wantCalls: 1,
+		},
+		{
+			name:       "client error not retried",
+			method:     http.MethodGet,
+			responses:  []func() (*http.Response, error){status(404), status(200)},
+			wantStatus: 404, wantCalls: 1,
+		},
+		{
+			name:       "POST not retried",
+			method:     http.MethodPost,
+			body:       `{"q":1}`,
+			responses:  []func() (*http.Response, error){status(503), status(200)},
+			wantStatus: 503, wantCalls: 1,
+		},
+		{
+			name:      "POST error not retried",
+			method:    http.MethodPost,
+			body:      `{"q":1}`,
+			responses: []func() (*http.Response, error){fail, status(200)},
+			wantErr:   true, wantCalls: 1,
+		},
+		{
+			name:        "POST retried with RetryUnsafe",
+			method:      http.MethodPost,
+			body:        `{"q":1}`,
+			retryUnsafe: true,
+			responses:   []func() (*http.Response, error){status(503), status(200)},
+			wantStatus:  200, wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &script{responses: tt.responses}
+			tr := httpclient.NewTransport(s, fast, map[string]httpclient.Upstream{
+				"upstream.test": {Name: "test", RetryUnsafe: tt.retryUnsafe},
+			})
+			resp, err := send(t, tr, tt.method, tt.body)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if n := s.calls(); n != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", n, tt.wantCalls)
+			}
+			for i, b := range s.bodies {
+				if b != tt.body {
+					t.Errorf("attempt %d body = %q, want %q", i+1, b, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	release := make(chan struct{})
+	probing := make(chan struct{})
+	s := &script{responses: []func() (*http.Response, error){
+		fail, fail,
+		// The probe after the cooldown blocks until the test lets it succeed.
+		func() (*http.Response, error) {
+			close(probing)
+			<-release
+			return status(200)()
+		},
+		status(200),
+	}}
+	tr := httpclient.NewTransport(s, httpclient.Options{BreakerThreshold: 2, BreakerCooldown: cooldown}, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := send(t, tr, http.MethodGet, ""); err == nil {
+			t.Fatalf("request %d succeeded", i+1)
+		}
+	}
+	var open *httpclient.CircuitOpenError
+	if _, err := send(t, tr, http.MethodGet, ""); !errors.As(err, &open) {
+		t.Fatalf("after threshold: err = %v, want CircuitOpenError", err)
+	}
+	if open.Upstream != "upstream.test" {
+		t.Errorf("Upstream = %q", open.Upstream)
+	}
+	if n := s.calls(); n != 2 {
+		t.Fatalf("open breaker reached upstream: calls = %d", n)
+	}
+
+	time.Sleep(cooldown)
+	done := make(chan error, 1)
+	go func() {
+		_, err := send(t, tr, http.MethodGet, "")
+		done <- err
+	}()
+	<-probing
+	// Only one probe is let through while it is in flight.
+	if _, err := send(t, tr, http.MethodGet, ""); !errors.As(err, &open) {
+		t.Errorf("during probe: err = %v, want CircuitOpenError", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+
+	// The successful probe closed the breaker.
+	if resp, err := send(t, tr, http.MethodGet, ""); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("after probe: %v", err)
+	}
+	if n := s.calls(); n != 4 {
+		t.Errorf("calls = %d, want 4", n)
+	}
+}
+
+func TestBreakerProbeFailureReopens(t *testing.T) {
+	const cooldown = 30 * time.Millisecond
+	s := &script{responses: []func() (*http.Response, error){status(500)}}
+	tr := httpclient.NewTransport(s, httpclient.Options{BreakerThreshold: 1, BreakerCooldown: cooldown}, nil)
+
+	send(t, tr, http.MethodGet, "")
+	time.Sleep(cooldown)
+	if resp, err := send(t, tr, http.MethodGet, ""); err != nil || resp.StatusCode != 500 {
+		t.Fatalf("probe: %v", err)
+	}
+	var open *httpclient.CircuitOpenError
+	if _, err := send(t, tr, http.MethodGet, ""); !errors.As(err, &open) {
+		t.Errorf("after failed probe: err = %v, want CircuitOpenError", err)
+	}
+	if n := s.calls(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestMinInterval(t *testing.T) {
+	const interval = 40 * time.Millisecond
+	s := &script{responses: []func() (*http.Response, error){status(200)}}
+	tr := httpclient.NewTransport(s, httpclient.Options{}, map[string]httpclient.Upstream{
+		"upstream.test": {Name: "test", MinInterval: interval},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send(t, tr, http.MethodGet, "")
+		}()
+	}
+	wg.Wait()
+
+	if len(s.times) != 3 {
+		t.Fatalf("calls = %d, want 3", len(s.times))
+	}
+	for i := 1; i < len(s.times); i++ {
+		// Allow for timer granularity.
+		if gap := s.times[i].Sub(s.times[i-1]); gap < interval-5*time.Millisecond {
+			t.Errorf("gap %d = %s, want at least %s", i, gap, interval)
+		}
+	}
+}
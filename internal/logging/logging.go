@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Configure sets the process-wide logrus format ("text" or "json") and level.
+// Text output is colored only when stderr is a terminal.
+func Configure(format, level string) error {
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(lvl)
+
+	switch strings.ToLower(format) {
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	case "text", "":
+		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	return nil
+}
+
+type ctxKey struct{}
+
+// WithLogger returns a copy of ctx carrying entry.
+func WithLogger(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext returns the request-scoped logger stored by Middleware, or the
+// standard logger when ctx carries none, so it is always safe to use.
+func FromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(ctxKey{}).(*log.Entry); ok {
+		return entry.WithContext(ctx)
+	}
+	return log.NewEntry(log.StandardLogger()).WithContext(ctx)
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is accepted from clients and always set on responses.
+const RequestIDHeader = "X-Request-ID"
+
+const requestIDKey = "request_id"
+
+// Middleware assigns every request an ID (the client's X-Request-ID when it
+// is sane, a random one otherwise), echoes it in the response, stores a
+// logger carrying it and the trace ID in the request context, and writes one
+// access log line when the request completes.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := RequestIDOrNew(c.GetHeader(RequestIDHeader))
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		fields := log.Fields{"request_id": id}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			fields["trace_id"] = sc.TraceID().String()
+		}
+		entry := log.WithFields(fields)
+		c.Request = c.Request.WithContext(WithLogger(c.Request.Context(), entry))
+
+		start := time.Now()
+		c.Next()
+
+		access := entry.WithFields(log.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"route":      c.FullPath(),
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+			"bytes":      c.Writer.Size(),
+		})
+		if len(c.Errors) > 0 {
+			access = access.WithField("errors", c.Errors.String())
+		}
+		switch {
+		case c.Writer.Status() >= 500:
+			access.Error("request completed")
+		case c.Writer.Status() >= 400:
+			access.Warn("request completed")
+		default:
+			access.Info("request completed")
+		}
+	}
+}
+
+// RequestID returns the ID assigned by Middleware, or "" outside a request.
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// RequestIDOrNew returns id when it is usable as a request ID and a new
+// random one otherwise.
+func RequestIDOrNew(id string) string {
+	if validRequestID(id) {
+		return id
+	}
+	return newRequestID()
+}
+
+// validRequestID accepts up to 128 visible ASCII characters so a client ID
+// cannot inject newlines or control sequences into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
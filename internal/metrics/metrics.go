@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/publicthrone547/towards_project/internal/ai"
+	"github.com/publicthrone547/towards_project/internal/cache"
+)
+
+const namespace = "towards"
+
+// Metrics owns a private Prometheus registry and the collectors recorded by
+// the HTTP middleware, the outbound transport and the AI client. Each Server
+// gets its own so several can run in one process.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	upstreamRequests *prometheus.CounterVec
+	upstreamDuration *prometheus.HistogramVec
+
+	llmRequests *prometheus.CounterVec
+	llmTokens   *prometheus.CounterVec
+	llmDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to serve HTTP requests, by route.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"method", "route"}),
+		upstreamRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Outbound requests by provider and outcome (2xx, 4xx, 5xx or error).",
+		}, []string{"provider", "outcome"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Outbound request latency by provider, until response headers.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"provider"}),
+		llmRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_requests_total",
+			Help:      "LLM generate calls by model and outcome.",
+		}, []string{"model", "outcome"}),
+		llmTokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_tokens_total",
+			Help:      "LLM tokens reported by the provider, by model and type (prompt or completion).",
+		}, []string{"model", "type"}),
+		llmDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "llm_request_duration_seconds",
+			Help:      "LLM generate call latency by model.",
+			Buckets:   []float64{.25, .5, 1, 2.5, 5, 10, 20, 30, 60},
+		}, []string{"model"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration,
+		m.upstreamRequests, m.upstreamDuration,
+		m.llmRequests, m.llmTokens, m.llmDuration,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records request count and latency labelled by the gin route
+// pattern, so /countries/:code is one series regardless of the code.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.requestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterCache exports hit/miss counters and the entry count of c.
+func (m *Metrics) RegisterCache(c *cache.Cache) {
+	m.registry.MustRegister(&cacheCollector{cache: c})
+}
+
+// RecordLLM implements ai.Recorder.
+func (m *Metrics) RecordLLM(model, outcome string, duration time.Duration, usage ai.Usage) {
+	m.llmRequests.WithLabelValues(model, outcome).Inc()
+	m.llmDuration.WithLabelValues(model).Observe(duration.Seconds())
+	if usage.PromptTokens > 0 {
+		m.llmTokens.WithLabelValues(model, "prompt").Add(float64(usage.PromptTokens))
+	}
+	if usage.CompletionTokens > 0 {
+		m.llmTokens.WithLabelValues(model, "completion").Add(float64(usage.CompletionTokens))
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Transport wraps next so every outbound request is counted and timed under
+// the provider name mapped from its host. Hosts missing from providers are
+// reported as "other" to keep label cardinality bounded.
+func (m *Metrics) Transport(next http.RoundTripper, providers map[string]string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next, metrics: m, providers: providers}
+}
+
+type transport struct {
+	next      http.RoundTripper
+	metrics   *Metrics
+	providers map[string]string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider, ok := t.providers[req.URL.Host]
+	if !ok {
+		provider = "other"
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.metrics.upstreamDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+
+	outcome := "error"
+	if err == nil {
+		switch {
+		case resp.StatusCode >= 500:
+			outcome = "5xx"
+		case resp.StatusCode >= 400:
+			outcome = "4xx"
+		default:
+			outcome = "2xx"
+		}
+	}
+	t.metrics.upstreamRequests.WithLabelValues(provider, outcome).Inc()
+	return resp, err
+}
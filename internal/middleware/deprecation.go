@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated marks every response as coming from a deprecated route. The
+// Deprecation header uses the RFC 9745 "@<unix seconds>" form and Sunset the
+// RFC 8594 HTTP-date; a zero sunset omits it. successor maps the request
+// path to its replacement, which is advertised in a Link header with
+// rel="successor-version".
+func Deprecated(at, sunset time.Time, successor func(path string) string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(at.Unix(), 10)
+	sunsetValue := ""
+	if !sunset.IsZero() {
+		sunsetValue = sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("Deprecation", deprecation)
+		if sunsetValue != "" {
+			h.Set("Sunset", sunsetValue)
+		}
+		if s := successor(c.Request.URL.Path); s != "" {
+			h.Add("Link", "<"+s+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
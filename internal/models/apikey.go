@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// APIKey is an issued client key. Only the SHA-256 hash of the key is stored;
+// quotas are per UTC day and 0 means unlimited.
+type APIKey struct {
+	ID           int64      `db:"id" json:"id"`
+	Name         string     `db:"name" json:"name"`
+	KeyPrefix    string     `db:"key_prefix" json:"key_prefix"`
+	KeyHash      string     `db:"key_hash" json:"-"`
+	AIQuota      int        `db:"ai_quota" json:"ai_quota"`
+	WeatherQuota int        `db:"weather_quota" json:"weather_quota"`
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	LastUsedAt   *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+type APIKeyUsage struct {
+	KeyID int64     `db:"key_id" json:"key_id"`
+	Day   time.Time `db:"day" json:"day"`
+	Kind  string    `db:"kind" json:"kind"`
+	Count int       `db:"count" json:"count"`
+}
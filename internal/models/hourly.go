@@ -0,0 +1,14 @@
+package models
+
+// HourlyWeather is a provider-independent hourly forecast entry.
+type HourlyWeather struct {
+	Time         string  `db:"time" json:"time"`
+	Temperature  float64 `db:"temperature" json:"temperature"`
+	FeelsLike    float64 `db:"feels_like" json:"feels_like"`
+	Humidity     float64 `db:"humidity" json:"humidity"`
+	PrecipProb   float64 `db:"precip_prob" json:"precip_prob"`
+	WindSpeed    float64 `db:"wind_speed" json:"wind_speed"`
+	UVIndex      float64 `db:"uv_index" json:"uv_index"`
+	Conditions   string  `db:"conditions" json:"conditions"`
+	ComfortScore float64 `db:"comfort_score" json:"comfort_score"`
+}
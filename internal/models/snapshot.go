@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CitySnapshot is a stored result of a scheduled city refresh. The embedded
+// models hold the headline metrics; Payload is the full weather response.
+type CitySnapshot struct {
+	ID   int64  `db:"id" json:"id"`
+	City string `db:"city" json:"city"`
+	Weather
+	Air
+	Traffic
+	Crime
+	Total
+	Payload     []byte    `db:"payload" json:"-"`
+	CollectedAt time.Time `db:"collected_at" json:"collected_at"`
+}
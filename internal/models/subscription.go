@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Subscription struct {
+	ID               int64          `db:"id" json:"id"`
+	APIKeyID         *int64         `db:"api_key_id" json:"-"` // owner; nil when created without auth
+	City             string         `db:"city" json:"city"`
+	WebhookURL       string         `db:"webhook_url" json:"webhook_url"`
+	Secret           string         `db:"secret" json:"-"`
+	MinComfortIndex  *float64       `db:"min_comfort_index" json:"min_comfort_index,omitempty"`
+	MinSeverity      string         `db:"min_severity" json:"min_severity"`
+	Active           bool           `db:"active" json:"active"`
+	LastComfortIndex *float64       `db:"last_comfort_index" json:"last_comfort_index,omitempty"`
+	LastAlertIDs     pq.StringArray `db:"last_alert_ids" json:"last_alert_ids"`
+	LastCheckedAt    *time.Time     `db:"last_checked_at" json:"last_checked_at,omitempty"`
+	CreatedAt        time.Time      `db:"created_at" json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64     `db:"id" json:"id"`
+	SubscriptionID int64     `db:"subscription_id" json:"subscription_id"`
+	Event          string    `db:"event" json:"event"`
+	Payload        []byte    `db:"payload" json:"-"`
+	StatusCode     *int      `db:"status_code" json:"status_code,omitempty"`
+	Attempts       int       `db:"attempts" json:"attempts"`
+	Error          *string   `db:"error" json:"error,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
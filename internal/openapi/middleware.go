@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+)
+
+// Validator checks requests against the spec before they reach a handler.
+type Validator struct {
+	router routers.Router
+}
+
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router}, nil
+}
+
+// Middleware rejects requests whose parameters or JSON body do not match the
+// operation's schema with a problem response. Routes missing from the spec
+// (/metrics, the docs themselves) pass through. Authentication is left to the
+// auth middleware.
+func (v *Validator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := v.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				MultiError:         false,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			apierror.Abort(c, validationError(err))
+			return
+		}
+		c.Next()
+	}
+}
+
+func validationError(err error) *apierror.Error {
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) {
+		if p := reqErr.Parameter; p != nil {
+			code := apierror.CodeInvalidRequest
+			switch {
+			case p.Name == "date":
+				code = apierror.CodeInvalidDate
+			case errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired):
+				code = apierror.CodeMissingParameter
+			}
+			return apierror.Wrap(code, err, "%s parameter %q: %s", p.In, p.Name, reasonOf(reqErr)).With("parameter", p.Name)
+		}
+		if reqErr.RequestBody != nil {
+			return apierror.Wrap(apierror.CodeInvalidRequest, err, "request body: %s", reasonOf(reqErr))
+		}
+	}
+	return apierror.Wrap(apierror.CodeInvalidRequest, err, "%s", err.Error())
+}
+
+func reasonOf(e *openapi3filter.RequestError) string {
+	var se *openapi3.SchemaError
+	if errors.As(e.Err, &se) {
+		if len(se.JSONPointer()) > 0 {
+			return se.Reason + " at /" + strings.Join(se.JSONPointer(), "/")
+		}
+		return se.Reason
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Reason
+}
+
+// Handler serves the spec as JSON.
+func Handler(doc *openapi3.T) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// Docs serves an HTML page rendering /openapi.json with Redoc.
+func Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+}
+
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Towards API</title>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="/openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
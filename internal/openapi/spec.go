@@ -0,0 +1,323 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/countries"
+	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+// param describes a query or path parameter.
+type param struct {
+	in, name, description string
+	required              bool
+	pattern               string
+}
+
+// Versions are the API versions mounted under /<version>. A version only
+// needs its own operations where a request or response shape changed.
+var Versions = []string{"v1", "v2"}
+
+// LegacyVersion is the version the deprecated unversioned routes alias.
+const LegacyVersion = "v1"
+
+// operation is one documented route. Request and response schemas are
+// generated from the Go types the handlers bind and render, so the spec
+// cannot drift from the code.
+type operation struct {
+	method, path, id, summary, tag string
+	security                       string // "", "apiKey" or "adminToken"
+	// versions lists the API versions serving the operation; nil means all.
+	// Unversioned operations (admin and ops) are mounted at the root only.
+	versions    []string
+	unversioned bool
+	params      []param
+	request     interface{}
+	status      int
+	response    interface{} // nil for an empty body
+	errors      []int
+}
+
+var (
+	cityParam = param{in: "query", name: "city", description: "City name as understood by the weather provider", required: true}
+	dateParam = param{in: "query", name: "date", description: "Day to report, DD-MM-YYYY or YYYY-MM-DD; today when omitted",
+		pattern: `^(\d{2}-\d{2}-\d{4}|\d{4}-\d{2}-\d{2})$`}
+	idParam   = param{in: "path", name: "id", required: true, pattern: `^[0-9]+$`}
+	codeParam = param{in: "path", name: "code", description: "ISO 3166-1 alpha-2 or alpha-3 country code", required: true,
+		pattern: `^[A-Za-z]{2,3}$`}
+)
+
+var operations = []operation{
+	{method: http.MethodGet, path: "/weather", id: "getWeather", tag: "weather", security: "apiKey", versions: []string{"v1"},
+		summary: "Weather, comfort index, enrichments and AI forecast for a city",
+		params:  []param{cityParam, dateParam}, status: http.StatusOK, response: handlers.WeatherResponse{},
+		errors: []int{400, 401, 404, 429, 502, 504}},
+	{method: http.MethodGet, path: "/weather", id: "getWeather", tag: "weather", security: "apiKey", versions: []string{"v2"},
+		summary: "Weather with typed hours and metrics grouped into nested objects",
+		params:  []param{cityParam, dateParam}, status: http.StatusOK, response: handlers.WeatherResponseV2{},
+		errors: []int{400, 401, 404, 429, 502, 504}},
+	{method: http.MethodGet, path: "/alerts", id: "getAlerts", tag: "weather", security: "apiKey",
+		summary: "Active severe-condition alerts for a city",
+		params:  []param{cityParam, dateParam}, status: http.StatusOK, response: handlers.AlertsResponse{},
+		errors: []int{400, 401, 404, 429, 502, 504}},
+
+	{method: http.MethodGet, path: "/countries/{code}", id: "getCountry", tag: "countries", security: "apiKey",
+		summary: "Country profile with the latest World Bank development indicators",
+		params:  []param{codeParam}, status: http.StatusOK, response: countries.Profile{},
+		errors: []int{400, 401, 404, 429, 502, 504}},
+
+	{method: http.MethodPost, path: "/subscriptions", id: "createSubscription", tag: "subscriptions", security: "apiKey",
+		summary: "Subscribe a webhook to comfort drops and alerts for a city",
+		request: handlers.SubscriptionRequest{}, status: http.StatusCreated, response: handlers.SubscriptionResponse{},
+		errors: []int{400, 401, 503}},
+	{method: http.MethodGet, path: "/subscriptions", id: "listSubscriptions", tag: "subscriptions", security: "apiKey",
+		summary: "List subscriptions", status: http.StatusOK, response: []models.Subscription{},
+		errors: []int{401, 503}},
+	{method: http.MethodDelete, path: "/subscriptions/{id}", id: "deleteSubscription", tag: "subscriptions", security: "apiKey",
+		summary: "Delete a subscription", params: []param{idParam}, status: http.StatusNoContent,
+		errors: []int{400, 401, 404, 503}},
+
+	{method: http.MethodPost, path: "/ask", id: "ask", tag: "ai", security: "apiKey",
+		summary: "Ask the city improvement assistant",
+		request: handlers.AskRequest{}, status: http.StatusOK, response: handlers.AskResponse{},
+		errors: []int{400, 401, 422, 429, 502, 503, 504}},
+	{method: http.MethodPost, path: "/improve", id: "improve", tag: "ai", security: "apiKey",
+		summary: "Short improvement suggestions for a city",
+		request: handlers.ImproveRequest{}, status: http.StatusOK, response: handlers.ImproveResponse{},
+		errors: []int{400, 401, 422, 429, 502, 503, 504}},
+
+	{method: http.MethodPost, path: "/graphql", id: "graphql", tag: "graphql", unversioned: true, security: "apiKey",
+		summary: "Query city metrics with GraphQL; only the selected fields are fetched from upstreams",
+		request: handlers.GraphQLRequest{}, status: http.StatusOK, response: handlers.GraphQLResponse{},
+		errors: []int{400, 401, 429}},
+
+	{method: http.MethodPost, path: "/admin/keys", id: "createAPIKey", tag: "admin", unversioned: true, security: "adminToken",
+		summary: "Issue an API key", request: handlers.CreateAPIKeyRequest{},
+		status: http.StatusCreated, response: handlers.CreateAPIKeyResponse{}, errors: []int{400, 401, 503}},
+	{method: http.MethodGet, path: "/admin/keys", id: "listAPIKeys", tag: "admin", unversioned: true, security: "adminToken",
+		summary: "List API keys", status: http.StatusOK, response: []models.APIKey{}, errors: []int{401, 503}},
+	{method: http.MethodDelete, path: "/admin/keys/{id}", id: "revokeAPIKey", tag: "admin", unversioned: true, security: "adminToken",
+		summary: "Revoke an API key", params: []param{idParam}, status: http.StatusNoContent, errors: []int{400, 401, 404, 503}},
+	{method: http.MethodGet, path: "/admin/keys/{id}/usage", id: "getAPIKeyUsage", tag: "admin", unversioned: true, security: "adminToken",
+		summary: "Daily usage of an API key", params: []param{idParam},
+		status: http.StatusOK, response: []models.APIKeyUsage{}, errors: []int{400, 401, 503}},
+
+	{method: http.MethodGet, path: "/healthz", id: "liveness", tag: "ops", unversioned: true, summary: "Liveness probe", status: http.StatusOK},
+	{method: http.MethodGet, path: "/readyz", id: "readiness", tag: "ops", unversioned: true, summary: "Readiness probe", status: http.StatusOK, errors: []int{503}},
+}
+
+// Spec builds the OpenAPI 3 document for the API. With legacy set the
+// unversioned aliases of LegacyVersion are documented as deprecated.
+func Spec(legacy bool) (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Towards city metrics API",
+			Version:     "1.0.0",
+			Description: "City weather, comfort and risk metrics with AI-generated summaries. Errors are RFC 7807 problem documents with a stable `code`.",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"apiKey": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().
+					WithType("apiKey").WithIn("header").WithName("X-API-Key")},
+				"adminToken": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().
+					WithType("apiKey").WithIn("header").WithName("X-Admin-Token")},
+			},
+		},
+	}
+
+	problem, err := schemaComponent(doc, reflect.TypeOf(apierror.Problem{}))
+	if err != nil {
+		return nil, err
+	}
+	if code := problem.Value.Properties["code"]; code != nil && code.Value != nil {
+		var codes []string
+		for c := range apierror.Codes() {
+			codes = append(codes, string(c))
+		}
+		sort.Strings(codes)
+		for _, c := range codes {
+			code.Value.Enum = append(code.Value.Enum, c)
+		}
+	}
+
+	for _, op := range operations {
+		if op.unversioned {
+			if err := addOperation(doc, problem, op, op.path, op.id, false); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, v := range Versions {
+			if !op.servedBy(v) {
+				continue
+			}
+			if err := addOperation(doc, problem, op, "/"+v+op.path, v+"_"+op.id, false); err != nil {
+				return nil, err
+			}
+		}
+		if legacy && op.servedBy(LegacyVersion) {
+			if err := addOperation(doc, problem, op, op.path, "legacy_"+op.id, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
+
+func (op operation) servedBy(version string) bool {
+	if op.versions == nil {
+		return true
+	}
+	for _, v := range op.versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// addOperation documents op at path under operation ID id.
+func addOperation(doc *openapi3.T, problem *openapi3.SchemaRef, op operation, path, id string, deprecated bool) error {
+	o := &openapi3.Operation{
+		OperationID: id,
+		Deprecated:  deprecated,
+		Summary:     op.summary,
+		Tags:        []string{op.tag},
+		Responses:   openapi3.NewResponses(),
+	}
+	if op.security != "" {
+		o.Security = &openapi3.SecurityRequirements{{op.security: []string{}}}
+	}
+	for _, p := range op.params {
+		schema := openapi3.NewStringSchema()
+		if p.pattern != "" {
+			schema.Pattern = p.pattern
+		}
+		o.Parameters = append(o.Parameters, &openapi3.ParameterRef{Value: &openapi3.Parameter{
+			In: p.in, Name: p.name, Description: p.description, Required: p.required,
+			Schema: schema.NewRef(),
+		}})
+	}
+	if op.request != nil {
+		s, err := typeSchema(doc, op.request)
+		if err != nil {
+			return fmt.Errorf("%s %s request: %w", op.method, path, err)
+		}
+		o.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(true).WithJSONSchemaRef(s)}
+	}
+
+	resp := openapi3.NewResponse().WithDescription(http.StatusText(op.status))
+	if op.response != nil {
+		s, err := typeSchema(doc, op.response)
+		if err != nil {
+			return fmt.Errorf("%s %s response: %w", op.method, path, err)
+		}
+		resp.WithJSONSchemaRef(s)
+	}
+	o.Responses.Set(fmt.Sprint(op.status), &openapi3.ResponseRef{Value: resp})
+	for _, status := range op.errors {
+		r := openapi3.NewResponse().WithDescription(http.StatusText(status))
+		r.Content = openapi3.Content{apierror.ContentType: openapi3.NewMediaType().WithSchemaRef(problem)}
+		o.Responses.Set(fmt.Sprint(status), &openapi3.ResponseRef{Value: r})
+	}
+	doc.AddOperation(path, op.method, o)
+	return nil
+}
+
+// typeSchema returns a $ref to the component schema for v's type, wrapped in
+// an array schema for slices.
+func typeSchema(doc *openapi3.T, v interface{}) (*openapi3.SchemaRef, error) {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Slice {
+		item, err := schemaComponent(doc, t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		arr := openapi3.NewArraySchema()
+		arr.Items = item
+		return arr.NewRef(), nil
+	}
+	return schemaComponent(doc, t)
+}
+
+// schemaComponent generates the schema for t into components/schemas under
+// its Go type name and returns a reference to it.
+func schemaComponent(doc *openapi3.T, t reflect.Type) (*openapi3.SchemaRef, error) {
+	name := t.Name()
+	if _, ok := doc.Components.Schemas[name]; !ok {
+		s, err := openapi3gen.NewSchemaRefForValue(reflect.New(t).Elem().Interface(), doc.Components.Schemas,
+			openapi3gen.UseAllExportedFields(), openapi3gen.SchemaCustomizer(requiredFromBinding))
+		if err != nil {
+			return nil, err
+		}
+		doc.Components.Schemas[name] = openapi3.NewSchemaRef("", s.Value)
+	}
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, doc.Components.Schemas[name].Value), nil
+}
+
+// requiredFromBinding marks fields with a gin `binding:"required"` tag as
+// required, so the spec enforces what the handlers already do.
+func requiredFromBinding(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !strings.Contains(f.Tag.Get("binding"), "required") {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		schema.Required = append(schema.Required, jsonName)
+	}
+	return nil
+}
+
+// CheckRoutes returns an error listing gin routes missing from doc, so a new
+// endpoint cannot ship undocumented. Paths in skip are exempt.
+func CheckRoutes(doc *openapi3.T, routes gin.RoutesInfo, skip ...string) error {
+	exempt := map[string]bool{}
+	for _, p := range skip {
+		exempt[p] = true
+	}
+	var missing []string
+	for _, rt := range routes {
+		if exempt[rt.Path] {
+			continue
+		}
+		item := doc.Paths.Find(ginToOpenAPIPath(rt.Path))
+		if item == nil || item.GetOperation(rt.Method) == nil {
+			missing = append(missing, rt.Method+" "+rt.Path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("routes missing from the OpenAPI spec: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ginToOpenAPIPath turns /keys/:id into /keys/{id}.
+func ginToOpenAPIPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// Policy lists the buckets checked for every request. Disabled limits are
+// skipped. Route limits are keyed by the gin route pattern without its API
+// version (e.g. "/weather" also covers /v1/weather) and shared by all clients
+// of that route.
+type Policy struct {
+	Global Limit
+	IP     Limit
+	Key    Limit
+	Routes map[string]Limit
+}
+
+type check struct {
+	key   string
+	limit Limit
+}
+
+// Request identifies the buckets one call takes a token from.
+type Request struct {
+	Method string // HTTP method
+	Route  string // route pattern, with or without its API version
+	IP     string
+	Key    string // raw API key, empty when none was sent
+}
+
+// Check takes a token from every bucket of p that applies to r. It returns
+// false with the exhausted bucket's result when any is empty, otherwise the
+// result of the most constrained bucket, nil when no limit applies. Limiter
+// errors are logged and the bucket is skipped. REST and gRPC share it.
+//
+// Buckets are taken in turn and the first empty one stops the check, so the
+// caller's own key and IP buckets come first: a client over its limit must
+// not keep draining the route and global buckets everyone shares.
+func Check(ctx context.Context, l Limiter, p Policy, r Request) (*Result, bool) {
+	var checks []check
+	if r.Key != "" && p.Key.Enabled() {
+		checks = append(checks, check{"key:" + auth.HashKey(r.Key), p.Key})
+	}
+	if p.IP.Enabled() && r.IP != "" {
+		checks = append(checks, check{"ip:" + r.IP, p.IP})
+	}
+	route := unversioned(r.Route)
+	if lim, ok := p.Routes[route]; ok && lim.Enabled() {
+		checks = append(checks, check{"route:" + r.Method + " " + route, lim})
+	}
+	if p.Global.Enabled() {
+		checks = append(checks, check{"global", p.Global})
+	}
+
+	var tightest *Result
+	for _, ch := range checks {
+		res, err := l.Allow(ctx, ch.key, ch.limit)
+		if err != nil {
+			log.Errorf("rate limit %s: %v", ch.key, err)
+			continue
+		}
+		if !res.Allowed {
+			return &res, false
+		}
+		if tightest == nil || remainingRatio(res) < remainingRatio(*tightest) {
+			r := res
+			tightest = &r
+		}
+	}
+	return tightest, true
+}
+
+// Middleware enforces p using l. It responds 429 with Retry-After when any
+// bucket is empty and sets X-RateLimit-* headers from the most constrained
+// bucket.
+func Middleware(l Limiter, p Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, ok := Check(c.Request.Context(), l, p, Request{
+			Method: c.Request.Method,
+			Route:  c.FullPath(),
+			IP:     c.ClientIP(),
+			Key:    auth.KeyFromRequest(c.Request),
+		})
+		if res != nil {
+			setHeaders(c, *res)
+		}
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds(*res)))
+			apierror.Abort(c, Exceeded(*res))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Exceeded is the API error for a denied call.
+func Exceeded(r Result) *apierror.Error {
+	return apierror.New(apierror.CodeRateLimited, "rate limit exceeded").With("retry_after", RetryAfterSeconds(r))
+}
+
+// RetryAfterSeconds is the Retry-After value for a denied call.
+func RetryAfterSeconds(r Result) int {
+	return ceilSeconds(r.RetryAfter)
+}
+
+// unversioned strips a leading /v<N> segment from a route pattern.
+func unversioned(route string) string {
+	rest, ok := strings.CutPrefix(route, "/v")
+	if !ok {
+		return route
+	}
+	i := strings.IndexByte(rest, '/')
+	if i <= 0 {
+		return route
+	}
+	if _, err := strconv.Atoi(rest[:i]); err != nil {
+		return route
+	}
+	return rest[i:]
+}
+
+func setHeaders(c *gin.Context, r Result) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(r.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(r.Reset)))
+}
+
+func remainingRatio(r Result) float64 {
+	if r.Limit == 0 {
+		return 1
+	}
+	return float64(r.Remaining) / float64(r.Limit)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
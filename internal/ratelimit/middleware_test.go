@@ -0,0 +1,63 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/publicthrone547/towards_project/internal/ratelimit"
+)
+
+// A client over its own limit must not spend the buckets shared with others.
+func TestCheckClientBucketsFirst(t *testing.T) {
+	ctx := context.Background()
+	p := ratelimit.Policy{
+		Global: ratelimit.Limit{Rate: 0.001, Burst: 5},
+		IP:     ratelimit.Limit{Rate: 0.001, Burst: 2},
+		Key:    ratelimit.Limit{Rate: 0.001, Burst: 2},
+		Routes: map[string]ratelimit.Limit{"/weather": {Rate: 0.001, Burst: 5}},
+	}
+	tests := []struct {
+		name  string
+		noisy ratelimit.Request
+	}{
+		{"ip", ratelimit.Request{Method: "GET", Route: "/v1/weather", IP: "203.0.113.1"}},
+		{"key", ratelimit.Request{Method: "GET", Route: "/weather", IP: "203.0.113.1", Key: "noisy-key"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := ratelimit.NewMemoryLimiter()
+			allowed := 0
+			for i := 0; i < 20; i++ {
+				if _, ok := ratelimit.Check(ctx, l, p, tt.noisy); ok {
+					allowed++
+				}
+			}
+			if allowed != 2 {
+				t.Fatalf("noisy client allowed %d times, want 2", allowed)
+			}
+
+			// Two requests went through, so three tokens are left in the
+			// route and global buckets for everyone else.
+			other := ratelimit.Request{Method: "GET", Route: "/weather", IP: "198.51.100.7"}
+			if res, ok := ratelimit.Check(ctx, l, p, other); !ok {
+				t.Fatalf("other client denied: %+v", res)
+			}
+			other.IP = "198.51.100.8"
+			for i := 0; i < 2; i++ {
+				if res, ok := ratelimit.Check(ctx, l, p, other); !ok {
+					t.Errorf("third client request %d denied: %+v", i+1, res)
+				}
+			}
+			if res, ok := ratelimit.Check(ctx, l, ratelimit.Policy{Global: p.Global}, other); ok || res.Limit != p.Global.Burst {
+				t.Errorf("global bucket not exhausted after 5 requests: %+v, %v", res, ok)
+			}
+		})
+	}
+}
+
+func TestCheckDisabled(t *testing.T) {
+	res, ok := ratelimit.Check(context.Background(), ratelimit.NewMemoryLimiter(), ratelimit.Policy{}, ratelimit.Request{IP: "203.0.113.1"})
+	if !ok || res != nil {
+		t.Errorf("got %+v, %v; want nil, true", res, ok)
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+// PostgresLimiter stores buckets in the rate_limit_buckets table so limits
+// are shared between instances. Each call locks the bucket row for the
+// duration of a short transaction and uses the database clock.
+type PostgresLimiter struct {
+	db *sqlx.DB
+}
+
+func NewPostgresLimiter(db *sqlx.DB) *PostgresLimiter {
+	return &PostgresLimiter{db: db}
+}
+
+func (p *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, NOW()) ON CONFLICT (key) DO NOTHING`,
+		key, limit.Burst); err != nil {
+		return Result{}, err
+	}
+
+	var row struct {
+		Tokens  float64   `db:"tokens"`
+		Updated time.Time `db:"updated_at"`
+		Now     time.Time `db:"now"`
+	}
+	if err := tx.GetContext(ctx, &row,
+		`SELECT tokens, updated_at, NOW() AS now FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`, key); err != nil {
+		return Result{}, err
+	}
+
+	tokens, res := take(row.Tokens, row.Now.Sub(row.Updated), limit)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`,
+		key, tokens, row.Now); err != nil {
+		return Result{}, err
+	}
+	return res, tx.Commit()
+}
+
+// Cleanup deletes buckets idle for longer than maxIdle every interval until
+// ctx is done.
+func (p *PostgresLimiter) Cleanup(ctx context.Context, interval, maxIdle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := p.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - $1 * INTERVAL '1 second'`, maxIdle.Seconds()); err != nil && ctx.Err() == nil {
+			log.Errorf("clean up rate limit buckets: %v", err)
+		}
+	}
+}
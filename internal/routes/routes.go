@@ -2,14 +2,76 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/publicthrone547/towards_project/internal/apierror"
+	"github.com/publicthrone547/towards_project/internal/auth"
 	"github.com/publicthrone547/towards_project/internal/handlers"
+	"github.com/publicthrone547/towards_project/internal/health"
+	"github.com/publicthrone547/towards_project/internal/metrics"
+	"github.com/publicthrone547/towards_project/internal/middleware"
+	"github.com/publicthrone547/towards_project/internal/openapi"
 )
 
-func Register(r *gin.Engine) {
+// Register mounts every route on r. It fails when the OpenAPI spec cannot be
+// built or does not document a registered route.
+func Register(r *gin.Engine, s *handlers.Server, a *auth.Authenticator, h *health.Checker, m *metrics.Metrics) error {
+	cfg := s.Config().API
+	doc, err := openapi.Spec(cfg.LegacyRoutes)
+	if err != nil {
+		return err
+	}
+	validator, err := openapi.NewValidator(doc)
+	if err != nil {
+		return err
+	}
+	r.Use(validator.Middleware())
+
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
-	r.GET("/weather", handlers.GetWeather)
-	r.POST("/ask", handlers.AskHandler)
-	r.POST("/improve", handlers.ImproveHandler)
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(apierror.NoRoute)
+	r.NoMethod(apierror.NoMethod)
+
+	r.GET("/healthz", h.Liveness)
+	r.GET("/readyz", h.Readiness)
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	r.GET("/openapi.json", openapi.Handler(doc))
+	r.GET("/docs", openapi.Docs)
+
+	for _, v := range openapi.Versions {
+		api(r.Group("/"+v), s, a, v)
+	}
+	if cfg.LegacyRoutes {
+		successor := func(path string) string { return "/" + openapi.LegacyVersion + path }
+		api(r.Group("", middleware.Deprecated(cfg.LegacyDeprecatedAt, cfg.LegacySunset, successor)), s, a, openapi.LegacyVersion)
+	}
+
+	// GraphQL is versioned through its schema rather than the URL.
+	r.POST("/graphql", a.Require(auth.KindWeather), s.GraphQL)
+
+	admin := r.Group("/admin", auth.Admin(s.Config().Auth.AdminToken))
+	admin.POST("/keys", s.CreateAPIKey)
+	admin.GET("/keys", s.ListAPIKeys)
+	admin.DELETE("/keys/:id", s.RevokeAPIKey)
+	admin.GET("/keys/:id/usage", s.GetAPIKeyUsage)
+
+	return openapi.CheckRoutes(doc, r.Routes(), "/", "/metrics", "/openapi.json", "/docs")
+}
+
+// api mounts the public routes of one API version. Versions share handlers
+// except where the response shape changed.
+func api(g *gin.RouterGroup, s *handlers.Server, a *auth.Authenticator, version string) {
+	weather := s.GetWeather
+	if version == "v2" {
+		weather = s.GetWeatherV2
+	}
+
+	g.GET("/weather", a.Require(auth.KindWeather), weather)
+	g.GET("/alerts", a.Require(auth.KindWeather), s.GetAlerts)
+	g.GET("/countries/:code", a.Require(auth.KindWeather), s.GetCountry)
+	g.POST("/subscriptions", a.Require(""), s.CreateSubscription)
+	g.GET("/subscriptions", a.Require(""), s.ListSubscriptions)
+	g.DELETE("/subscriptions/:id", a.Require(""), s.DeleteSubscription)
+	g.POST("/ask", a.Require(auth.KindAI), s.AskHandler)
+	g.POST("/improve", a.Require(auth.KindAI), s.ImproveHandler)
 }
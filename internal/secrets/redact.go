@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// Values shorter than this are not registered; replacing them would mangle
+// unrelated text.
+const minSecretLen = 6
+
+var (
+	mu     sync.RWMutex
+	values []string
+
+	// credential-looking query parameters, e.g. Visual Crossing's key=...
+	queryParamRe = regexp.MustCompile(`(?i)([?&](?:key|api_key|apikey|token|access_token)=)[^&\s"']+`)
+	// user:password in connection strings
+	userinfoRe = regexp.MustCompile(`(://[^/:@\s]+:)[^@\s]+@`)
+)
+
+// Register adds a value that Redact will hide.
+func Register(v string) {
+	if len(v) < minSecretLen {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, existing := range values {
+		if existing == v {
+			return
+		}
+	}
+	values = append(values, v)
+	// replace longer values first so overlapping secrets are fully hidden
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+}
+
+// Redact hides registered secrets, credential query parameters and URL
+// passwords in s.
+func Redact(s string) string {
+	mu.RLock()
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	mu.RUnlock()
+
+	s = queryParamRe.ReplaceAllString(s, "${1}"+redacted)
+	s = userinfoRe.ReplaceAllString(s, "${1}"+redacted+"@")
+	return s
+}
+
+// LogHook redacts log messages and string fields before they are written.
+type LogHook struct{}
+
+func (LogHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (LogHook) Fire(e *log.Entry) error {
+	e.Message = Redact(e.Message)
+	for k, v := range e.Data {
+		switch t := v.(type) {
+		case string:
+			e.Data[k] = Redact(t)
+		case error:
+			e.Data[k] = Redact(t.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Sources, in order of precedence, for a secret named NAME:
+//
+//  1. the NAME environment variable;
+//  2. the file named by NAME_FILE (Docker and Kubernetes secret mounts);
+//  3. the NAME entry of the encrypted file named by SECRETS_FILE, decrypted
+//     with the key in SECRETS_KEY or the file named by SECRETS_KEY_FILE.
+//
+// Every value found is registered for redaction.
+
+var (
+	storeOnce sync.Once
+	store     map[string]string
+	storeErr  error
+)
+
+// Lookup resolves a secret. ok is false when no source defines it.
+func Lookup(name string) (value string, ok bool, err error) {
+	if v, ok := os.LookupEnv(name); ok {
+		Register(v)
+		return v, true, nil
+	}
+
+	if path, ok := os.LookupEnv(name + "_FILE"); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		v := strings.TrimRight(string(data), "\r\n")
+		Register(v)
+		return v, true, nil
+	}
+
+	storeOnce.Do(func() { store, storeErr = loadEncryptedStore() })
+	if storeErr != nil {
+		return "", false, storeErr
+	}
+	if v, ok := store[name]; ok {
+		Register(v)
+		return v, true, nil
+	}
+	return "", false, nil
+}
+
+func loadEncryptedStore() (map[string]string, error) {
+	path := os.Getenv("SECRETS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	key, err := masterKey()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("SECRETS_FILE: %w", err)
+	}
+	values, err := Decrypt(key, data)
+	if err != nil {
+		return nil, fmt.Errorf("SECRETS_FILE %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func masterKey() (string, error) {
+	if k := os.Getenv("SECRETS_KEY"); k != "" {
+		return k, nil
+	}
+	if path := os.Getenv("SECRETS_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("SECRETS_KEY_FILE: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", fmt.Errorf("SECRETS_FILE is set but neither SECRETS_KEY nor SECRETS_KEY_FILE is")
+}
@@ -0,0 +1,187 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/alerts"
+	"github.com/publicthrone547/towards_project/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EventComfortDrop = "comfort_drop"
+	EventAlert       = "alert"
+)
+
+// Evaluator computes the current comfort index and active alerts for a city.
+type Evaluator func(ctx context.Context, city string) (float64, []alerts.Alert, error)
+
+type Payload struct {
+	Event          string         `json:"event"`
+	SubscriptionID int64          `json:"subscription_id"`
+	City           string         `json:"city"`
+	ComfortIndex   float64        `json:"comfort_index"`
+	Threshold      *float64       `json:"threshold,omitempty"`
+	Alerts         []alerts.Alert `json:"alerts,omitempty"`
+	SentAt         time.Time      `json:"sent_at"`
+}
+
+type Scheduler struct {
+	store     *Store
+	evaluate  Evaluator
+	deliverer *Deliverer
+	interval  time.Duration
+}
+
+func NewScheduler(store *Store, evaluate Evaluator, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		evaluate:  evaluate,
+		deliverer: NewDeliverer(),
+		interval:  interval,
+	}
+}
+
+// Run evaluates all active subscriptions every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Infof("Subscription scheduler started, interval %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			log.Info("Subscription scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce evaluates every active subscription once. Each city is evaluated a
+// single time no matter how many subscriptions reference it.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	subs, err := s.store.ListActive(ctx)
+	if err != nil {
+		log.Errorf("list subscriptions: %v", err)
+		return
+	}
+
+	type result struct {
+		comfort float64
+		alerts  []alerts.Alert
+		err     error
+	}
+	byCity := map[string]result{}
+
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			return
+		}
+		r, ok := byCity[sub.City]
+		if !ok {
+			r.comfort, r.alerts, r.err = s.evaluate(ctx, sub.City)
+			byCity[sub.City] = r
+		}
+		if r.err != nil {
+			log.Warnf("evaluate %s for subscription %d: %v", sub.City, sub.ID, r.err)
+			continue
+		}
+		s.process(ctx, sub, r.comfort, r.alerts)
+	}
+}
+
+// process notifies sub about a comfort drop below its threshold and alerts
+// it has not seen yet. State only advances past events that were delivered,
+// so a failed webhook is retried on the next run.
+func (s *Scheduler) process(ctx context.Context, sub models.Subscription, comfort float64, active []alerts.Alert) {
+	now := time.Now().UTC()
+
+	lastComfort := &comfort
+	if sub.MinComfortIndex != nil && comfort < *sub.MinComfortIndex {
+		wasAbove := sub.LastComfortIndex == nil || *sub.LastComfortIndex >= *sub.MinComfortIndex
+		if wasAbove {
+			err := s.notify(ctx, sub, Payload{
+				Event:          EventComfortDrop,
+				SubscriptionID: sub.ID,
+				City:           sub.City,
+				ComfortIndex:   comfort,
+				Threshold:      sub.MinComfortIndex,
+				SentAt:         now,
+			})
+			if err != nil {
+				lastComfort = sub.LastComfortIndex
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, id := range sub.LastAlertIDs {
+		seen[id] = true
+	}
+	var matching, fresh []alerts.Alert
+	for _, a := range active {
+		if !a.Severity.AtLeast(alerts.Severity(sub.MinSeverity)) {
+			continue
+		}
+		matching = append(matching, a)
+		if !seen[a.ID] {
+			fresh = append(fresh, a)
+		}
+	}
+	delivered := true
+	if len(fresh) > 0 {
+		err := s.notify(ctx, sub, Payload{
+			Event:          EventAlert,
+			SubscriptionID: sub.ID,
+			City:           sub.City,
+			ComfortIndex:   comfort,
+			Alerts:         fresh,
+			SentAt:         now,
+		})
+		delivered = err == nil
+	}
+
+	ids := make([]string, 0, len(matching))
+	for _, a := range matching {
+		if delivered || seen[a.ID] {
+			ids = append(ids, a.ID)
+		}
+	}
+	if err := s.store.UpdateState(ctx, sub.ID, lastComfort, ids, now); err != nil {
+		log.Errorf("update subscription %d state: %v", sub.ID, err)
+	}
+}
+
+// notify delivers p to sub's webhook and records the attempt. It returns
+// the delivery error, if any.
+func (s *Scheduler) notify(ctx context.Context, sub models.Subscription, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Errorf("marshal webhook payload: %v", err)
+		return err
+	}
+
+	status, attempts, deliverErr := s.deliverer.Deliver(ctx, sub.WebhookURL, sub.Secret, p.Event, body)
+	d := &models.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		Event:          p.Event,
+		Payload:        body,
+		Attempts:       attempts,
+	}
+	if status != 0 {
+		d.StatusCode = &status
+	}
+	if deliverErr != nil {
+		msg := deliverErr.Error()
+		d.Error = &msg
+		log.Warnf("webhook %s for subscription %d failed after %d attempts: %v", p.Event, sub.ID, attempts, deliverErr)
+	}
+	if err := s.store.RecordDelivery(ctx, d); err != nil {
+		log.Errorf("record webhook delivery: %v", err)
+	}
+	return deliverErr
+}
@@ -0,0 +1,97 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+var ErrNotFound = errors.New("subscription not found")
+
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) Create(ctx context.Context, sub *models.Subscription) error {
+	const q = `INSERT INTO subscriptions (api_key_id, city, webhook_url, secret, min_comfort_index, min_severity, active)
+		VALUES ($1, $2, $3, $4, $5, $6, TRUE)
+		RETURNING id, active, last_alert_ids, created_at`
+	return s.db.QueryRowxContext(ctx, q, sub.APIKeyID, sub.City, sub.WebhookURL, sub.Secret, sub.MinComfortIndex, sub.MinSeverity).
+		Scan(&sub.ID, &sub.Active, &sub.LastAlertIDs, &sub.CreatedAt)
+}
+
+func (s *Store) Get(ctx context.Context, id int64) (*models.Subscription, error) {
+	var sub models.Subscription
+	err := s.db.GetContext(ctx, &sub, `SELECT * FROM subscriptions WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// List returns the subscriptions owned by the API key owner, or the ownerless
+// ones, created with auth disabled, when owner is nil.
+func (s *Store) List(ctx context.Context, owner *int64) ([]models.Subscription, error) {
+	subs := []models.Subscription{}
+	err := s.db.SelectContext(ctx, &subs,
+		`SELECT * FROM subscriptions WHERE api_key_id IS NOT DISTINCT FROM $1 ORDER BY id`, owner)
+	return subs, err
+}
+
+func (s *Store) ListActive(ctx context.Context) ([]models.Subscription, error) {
+	subs := []models.Subscription{}
+	err := s.db.SelectContext(ctx, &subs, `SELECT * FROM subscriptions WHERE active ORDER BY city, id`)
+	return subs, err
+}
+
+// Delete removes subscription id if owner owns it, as List defines ownership.
+// Other owners' subscriptions are reported as ErrNotFound.
+func (s *Store) Delete(ctx context.Context, id int64, owner *int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM subscriptions WHERE id = $1 AND api_key_id IS NOT DISTINCT FROM $2`, id, owner)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UpdateState records the values seen on the latest evaluation so the next run
+// only notifies about changes. comfort may be nil, when no value has been
+// acknowledged yet.
+func (s *Store) UpdateState(ctx context.Context, id int64, comfort *float64, alertIDs []string, checkedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE subscriptions SET last_comfort_index = $2, last_alert_ids = $3, last_checked_at = $4 WHERE id = $1`,
+		id, comfort, pq.StringArray(alertIDs), checkedAt)
+	return err
+}
+
+func (s *Store) RecordDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	const q = `INSERT INTO webhook_deliveries (subscription_id, event, payload, status_code, attempts, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	return s.db.QueryRowxContext(ctx, q, d.SubscriptionID, d.Event, string(d.Payload), d.StatusCode, d.Attempts, d.Error).
+		Scan(&d.ID, &d.CreatedAt)
+}
+
+func (s *Store) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error) {
+	out := []models.WebhookDelivery{}
+	err := s.db.SelectContext(ctx, &out,
+		`SELECT * FROM webhook_deliveries WHERE subscription_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		subscriptionID, limit)
+	return out, err
+}
@@ -0,0 +1,69 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned for webhook targets on loopback, private,
+// link-local or unspecified addresses, which would let a subscriber make the
+// server call internal services.
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// publicAddr reports whether ip is a routable public address.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// CheckWebhookURL validates a subscription's webhook URL: absolute http(s),
+// and a host resolving only to public addresses. Deliveries check the
+// address again when dialing, since DNS can change after this check.
+func CheckWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("webhook_url must be an absolute http(s) URL")
+	}
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicAddr(ip) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("webhook_url host %q does not resolve", host)
+	}
+	for _, ip := range addrs {
+		if !publicAddr(ip) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// dialControl refuses connections to non-public addresses. It runs after
+// name resolution, so it also covers redirects and DNS rebinding.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(ap.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, ErrForbiddenTarget)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Towards-Signature"
+	TimestampHeader = "X-Towards-Timestamp"
+	EventHeader     = "X-Towards-Event"
+)
+
+// NewSecret returns a random hex secret used to sign a subscription's webhooks.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign computes the value of SignatureHeader for a payload sent at ts. The
+// receiver recomputes HMAC-SHA256(secret, "<timestamp>.<body>") and compares.
+func Sign(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Deliverer struct {
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration
+}
+
+// NewDeliverer returns a Deliverer whose client only connects to public
+// addresses. It ignores proxy settings, which would hide the target address
+// from the check.
+func NewDeliverer() *Deliverer {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialControl}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &Deliverer{
+		Client:      &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		MaxAttempts: 4,
+		BaseBackoff: time.Second,
+	}
+}
+
+// Deliver POSTs body to url, retrying with exponential backoff on transport
+// errors, 429 and 5xx responses. It returns the last status code (0 if no
+// response was received) and the number of attempts made.
+func (d *Deliverer) Deliver(ctx context.Context, url, secret, event string, body []byte) (int, int, error) {
+	var lastErr error
+	status := 0
+	backoff := d.BaseBackoff
+
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		status, lastErr = d.send(ctx, url, secret, event, body)
+		if lastErr == nil {
+			return status, attempt, nil
+		}
+		if errors.Is(lastErr, ErrForbiddenTarget) || (status != 0 && status != http.StatusTooManyRequests && status < 500) {
+			return status, attempt, lastErr
+		}
+		if attempt == d.MaxAttempts {
+			return status, attempt, lastErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, attempt, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return status, d.MaxAttempts, lastErr
+}
+
+func (d *Deliverer) send(ctx context.Context, url, secret, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "towards_project-webhooks/1.0")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, ts, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport wraps next so every outbound request runs in a client span named
+// after its provider. Only requests to the hosts in propagate carry the trace
+// context; third-party APIs get no traceparent or tracestate. Only the URL
+// path is recorded: query strings may contain API keys.
+func Transport(next http.RoundTripper, providers map[string]string, propagate map[string]bool) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next, providers: providers, propagate: propagate}
+}
+
+type transport struct {
+	next      http.RoundTripper
+	providers map[string]string
+	propagate map[string]bool
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider, ok := t.providers[req.URL.Host]
+	if !ok {
+		provider = "other"
+	}
+
+	ctx, span := tracer().Start(req.Context(), req.Method+" "+provider,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("upstream.provider", provider),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	if t.propagate[req.URL.Host] {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
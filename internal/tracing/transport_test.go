@@ -0,0 +1,64 @@
+package tracing_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/publicthrone547/towards_project/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// headers records the headers of the last request it was sent.
+type headers struct {
+	got http.Header
+}
+
+func (h *headers) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.got = req.Header.Clone()
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestTransportPropagation(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(t.Context())
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	providers := map[string]string{"gateway.internal": "gemini", "api.worldbank.org": "worldbank"}
+	propagate := map[string]bool{"gateway.internal": true, "api.worldbank.org": false}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://gateway.internal/v1beta/models/m:generateContent", true},
+		{"https://api.worldbank.org/v2/country/FRA", false},
+		{"https://unknown.example.com/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			next := &headers{}
+			rt := tracing.Transport(next, providers, propagate)
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, span := tracing.Start(req.Context(), "caller")
+			defer span.End()
+
+			resp, err := rt.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			resp.Body.Close()
+			if got := next.got.Get("traceparent") != ""; got != tt.want {
+				t.Errorf("traceparent sent = %v, want %v (headers %v)", got, tt.want, next.got)
+			}
+			if req.Header.Get("traceparent") != "" {
+				t.Error("caller's request was modified")
+			}
+		})
+	}
+}
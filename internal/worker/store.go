@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/publicthrone547/towards_project/internal/models"
+)
+
+var ErrNoSnapshot = errors.New("no snapshot")
+
+type Store struct {
+	db *sqlx.DB
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{db: db}
+}
+
+// CityKey normalises a city name so lookups match regardless of case or
+// surrounding whitespace.
+func CityKey(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+func (s *Store) Save(ctx context.Context, snap *models.CitySnapshot) error {
+	const q = `INSERT INTO city_snapshots
+		(city, temperature, quality_index, workload_points, safety_assessment, general_rating_of_comfort, payload)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, collected_at`
+	return s.db.QueryRowxContext(ctx, q,
+		CityKey(snap.City), snap.Temperature, snap.QualityIndex, snap.WorkloadPoints,
+		snap.SafetyAssessment, snap.GeneralRatingOfComfort, string(snap.Payload),
+	).Scan(&snap.ID, &snap.CollectedAt)
+}
+
+// Latest returns the newest snapshot for city collected within maxAge.
+func (s *Store) Latest(ctx context.Context, city string, maxAge time.Duration) (*models.CitySnapshot, error) {
+	var snap models.CitySnapshot
+	err := s.db.GetContext(ctx, &snap,
+		`SELECT * FROM city_snapshots WHERE city = $1 AND collected_at >= $2 ORDER BY collected_at DESC LIMIT 1`,
+		CityKey(city), time.Now().Add(-maxAge))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// Prune deletes snapshots older than retention.
+func (s *Store) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM city_snapshots WHERE collected_at < $1`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/publicthrone547/towards_project/internal/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Collector fetches fresh metrics for a city and returns them as a snapshot.
+type Collector func(ctx context.Context, city string) (*models.CitySnapshot, error)
+
+const snapshotRetention = 7 * 24 * time.Hour
+
+// Refresher periodically collects and stores snapshots for a fixed list of
+// cities.
+type Refresher struct {
+	store    *Store
+	collect  Collector
+	cities   []string
+	interval time.Duration
+}
+
+func NewRefresher(store *Store, collect Collector, cities []string, interval time.Duration) *Refresher {
+	return &Refresher{
+		store:    store,
+		collect:  collect,
+		cities:   cities,
+		interval: interval,
+	}
+}
+
+func (r *Refresher) Run(ctx context.Context) {
+	log.Infof("City refresher started for %d cities, interval %s", len(r.cities), r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RefreshAll(ctx)
+		select {
+		case <-ctx.Done():
+			log.Info("City refresher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Refresher) RefreshAll(ctx context.Context) {
+	for _, city := range r.cities {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := r.Refresh(ctx, city); err != nil {
+			log.Warnf("refresh %s: %v", city, err)
+		}
+	}
+
+	if n, err := r.store.Prune(ctx, snapshotRetention); err != nil {
+		log.Errorf("prune snapshots: %v", err)
+	} else if n > 0 {
+		log.Infof("Pruned %d old snapshots", n)
+	}
+}
+
+func (r *Refresher) Refresh(ctx context.Context, city string) error {
+	snap, err := r.collect(ctx, city)
+	if err != nil {
+		return err
+	}
+	snap.City = city
+	return r.store.Save(ctx, snap)
+}